@@ -0,0 +1,179 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const sessionLifetime = 30 * 24 * time.Hour
+const minPasswordLength = 8
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,20}$`)
+
+var ErrUsernameTaken = errors.New("username is taken")
+var ErrWrongPassword = errors.New("wrong username or password")
+
+// dummyPasswordHash is checked against when the username doesn't exist, so a
+// failed login takes as long whether or not the account exists.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
+type Account struct {
+	Name         string    `json:"name"`
+	PasswordHash []byte    `json:"password_hash"`
+	Created      time.Time `json:"created"`
+}
+
+// Session is keyed by the SHA-256 of its token, so the stored sessions can't
+// be used to log in.
+type Session struct {
+	User    string    `json:"user"`
+	Expires time.Time `json:"expires"`
+}
+
+// AccountsRecord is the persisted form of all accounts and sessions.
+type AccountsRecord struct {
+	Accounts map[string]*Account `json:"accounts"`
+	Sessions map[string]*Session `json:"sessions"`
+}
+
+type Accounts struct {
+	lock     sync.Mutex
+	accounts map[string]*Account
+	sessions map[string]*Session
+	store    Store
+}
+
+func LoadAccounts(store Store) (*Accounts, error) {
+	record, err := store.LoadAccounts()
+	if err != nil {
+		return nil, err
+	}
+	accounts := &Accounts{
+		lock:     sync.Mutex{},
+		accounts: record.Accounts,
+		sessions: record.Sessions,
+		store:    store,
+	}
+	if accounts.accounts == nil {
+		accounts.accounts = make(map[string]*Account)
+	}
+	if accounts.sessions == nil {
+		accounts.sessions = make(map[string]*Session)
+	}
+	return accounts, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (a *Accounts) saveLocked() error {
+	return a.store.SaveAccounts(&AccountsRecord{
+		Accounts: a.accounts,
+		Sessions: a.sessions,
+	})
+}
+
+// expireSessionsLocked drops every session that has expired.
+func (a *Accounts) expireSessionsLocked(now time.Time) {
+	for key, session := range a.sessions {
+		if now.After(session.Expires) {
+			delete(a.sessions, key)
+		}
+	}
+}
+
+func (a *Accounts) Register(name string, password string) error {
+	if !usernamePattern.MatchString(name) {
+		return fmt.Errorf("username must be 1-20 letters, digits, '-' or '_'")
+	}
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if _, found := a.accounts[name]; found {
+		return ErrUsernameTaken
+	}
+	a.accounts[name] = &Account{
+		Name:         name,
+		PasswordHash: hash,
+		Created:      time.Now(),
+	}
+	return a.saveLocked()
+}
+
+// Login checks the password and returns a new session token and its expiry.
+func (a *Accounts) Login(name string, password string) (string, time.Time, error) {
+	a.lock.Lock()
+	account, found := a.accounts[name]
+	a.lock.Unlock()
+	if !found {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return "", time.Time{}, ErrWrongPassword
+	}
+	if bcrypt.CompareHashAndPassword(account.PasswordHash, []byte(password)) != nil {
+		return "", time.Time{}, ErrWrongPassword
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	now := time.Now()
+	expires := now.Add(sessionLifetime)
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.expireSessionsLocked(now)
+	a.sessions[hashToken(token)] = &Session{
+		User:    name,
+		Expires: expires,
+	}
+	if err := a.saveLocked(); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expires, nil
+}
+
+func (a *Accounts) Logout(token string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	key := hashToken(token)
+	if _, found := a.sessions[key]; !found {
+		return nil
+	}
+	delete(a.sessions, key)
+	return a.saveLocked()
+}
+
+// Authenticate returns the user that owns the session token.
+func (a *Accounts) Authenticate(token string) (string, bool) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	key := hashToken(token)
+	session, found := a.sessions[key]
+	if !found {
+		return "", false
+	}
+	if time.Now().After(session.Expires) {
+		delete(a.sessions, key)
+		return "", false
+	}
+	return session.User, true
+}
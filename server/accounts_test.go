@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestAccounts(t *testing.T) (*Accounts, Store) {
+	t.Helper()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := LoadAccounts(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return accounts, store
+}
+
+func TestRegister(t *testing.T) {
+	accounts, _ := newTestAccounts(t)
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"alice", "password", true},
+		{"alice", "password", false},
+		{"", "password", false},
+		{"no spaces", "password", false},
+		{"twenty-one-characters", "password", false},
+		{"bob", "short", false},
+	}
+	for _, test := range tests {
+		err := accounts.Register(test.name, test.password)
+		if test.valid && err != nil {
+			t.Errorf("'%s': %v", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("'%s' with password '%s' was registered", test.name, test.password)
+		}
+	}
+}
+
+func TestLoginAndLogout(t *testing.T) {
+	accounts, store := newTestAccounts(t)
+	if err := accounts.Register("alice", "password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := accounts.Login("alice", "wrong password"); err == nil {
+		t.Error("logged in with the wrong password")
+	}
+	if _, _, err := accounts.Login("bob", "password"); err == nil {
+		t.Error("logged in without an account")
+	}
+	token, expires, err := accounts.Login("alice", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expires.Before(time.Now().Add(sessionLifetime - time.Minute)) {
+		t.Errorf("session expires too soon: %v", expires)
+	}
+
+	// Accounts and sessions survive a restart.
+	accounts, err = LoadAccounts(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, found := accounts.Authenticate(token); !found || user != "alice" {
+		t.Fatal("session was not restored")
+	}
+	if err := accounts.Logout(token); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := accounts.Authenticate(token); found {
+		t.Error("session outlived logging out")
+	}
+}
+
+func TestSessionExpires(t *testing.T) {
+	accounts, _ := newTestAccounts(t)
+	if err := accounts.Register("alice", "password"); err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := accounts.Login("alice", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts.sessions[hashToken(token)].Expires = time.Now().Add(-time.Minute)
+	if _, found := accounts.Authenticate(token); found {
+		t.Error("expired session was accepted")
+	}
+	if len(accounts.sessions) != 0 {
+		t.Error("expired session was kept")
+	}
+}
@@ -0,0 +1,473 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Bot chooses the actions of a computer player.
+type Bot interface {
+	// NextAction returns the next action for the active player. The state is
+	// redacted for that player and must not be modified.
+	NextAction(state *GameState, m *Map) (*Action, error)
+}
+
+const (
+	RandomBotKind    = "random"
+	HeuristicBotKind = "heuristic"
+)
+
+func NewBot(kind string) (Bot, error) {
+	switch kind {
+	case RandomBotKind:
+		return &RandomBot{rng: rand.New(rand.NewSource(rand.Int63()))}, nil
+	case HeuristicBotKind:
+		return &HeuristicBot{}, nil
+	}
+	return nil, fmt.Errorf("unknown bot '%s'", kind)
+}
+
+// ownedTerrits returns the player's territories, sorted by name.
+func ownedTerrits(state *GameState, player string) []string {
+	var territs []string
+	for name, territ := range state.Territs {
+		if territ.Owner == player {
+			territs = append(territs, name)
+		}
+	}
+	sort.Strings(territs)
+	return territs
+}
+
+// enemyNeighbours returns the neighbours of the territory that belong to
+// someone other than the player and their teammates.
+func enemyNeighbours(state *GameState, m *Map, territ string, player string) []string {
+	var enemies []string
+	for _, neighbour := range m.Territs[territ].Neighbours {
+		if !state.allied(player, state.Territs[neighbour.Name].Owner) {
+			enemies = append(enemies, neighbour.Name)
+		}
+	}
+	return enemies
+}
+
+// attacks returns every attack the player can make.
+func attacks(state *GameState, m *Map, player string) []*AttackAction {
+	var attacks []*AttackAction
+	for _, from := range ownedTerrits(state, player) {
+		if state.Territs[from].Troops <= 1 {
+			continue
+		}
+		for _, to := range enemyNeighbours(state, m, from, player) {
+			attacks = append(attacks, &AttackAction{Player: player, From: from, To: to})
+		}
+	}
+	return attacks
+}
+
+// spoilsSet returns the names of a set of spoils that can be played, or nil
+// if there is none.
+func spoilsSet(spoils []*Spoil) []string {
+	byColor := make(map[string][]string)
+	for _, spoil := range spoils {
+		byColor[spoil.Color] = append(byColor[spoil.Color], spoil.Name)
+	}
+	colors := []string{"red", "green", "blue"}
+	for _, color := range colors {
+		if len(byColor[color]) >= 3 {
+			return byColor[color][:3]
+		}
+	}
+	var set []string
+	for _, color := range colors {
+		if len(byColor[color]) == 0 {
+			return nil
+		}
+		set = append(set, byColor[color][0])
+	}
+	return set
+}
+
+// RandomBot plays a random legal move.
+type RandomBot struct {
+	rng *rand.Rand
+}
+
+func (b *RandomBot) NextAction(state *GameState, m *Map) (*Action, error) {
+	player := state.ActivePlayer
+	territs := ownedTerrits(state, player)
+	switch {
+	case state.Phase.Draft != nil:
+		if auction := state.Phase.Draft.Auction; auction != nil {
+			if state.findPlayer(player).Pool > auction.Bid && b.rng.Intn(3) == 0 {
+				return &Action{Bid: &BidAction{Player: player, Troops: auction.Bid + 1}}, nil
+			}
+			return &Action{Bid: &BidAction{Player: player}}, nil
+		}
+		unclaimed := state.unclaimedTerrits()
+		territ := unclaimed[b.rng.Intn(len(unclaimed))]
+		if state.Phase.Draft.Mode == BidDraft {
+			return &Action{Bid: &BidAction{Player: player, Territory: territ, Troops: 1}}, nil
+		}
+		return &Action{Claim: &ClaimAction{Player: player, Territory: territ}}, nil
+	case state.Phase.Placement != nil:
+		territ := territs[b.rng.Intn(len(territs))]
+		return &Action{Place: &DeployAction{
+			Player:      player,
+			Deployments: map[string]uint64{territ: state.Phase.Placement.Troops},
+		}}, nil
+	case state.Phase.Spoils != nil:
+		set := spoilsSet(state.findPlayer(player).Spoils)
+		if !state.Phase.Spoils.Mandatory && b.rng.Intn(2) == 0 {
+			set = nil
+		}
+		return &Action{Spoils: &SpoilsAction{Player: player, Spoils: set}}, nil
+	case state.Phase.Deploy != nil:
+		territ := territs[b.rng.Intn(len(territs))]
+		return &Action{Deploy: &DeployAction{
+			Player:      player,
+			Deployments: map[string]uint64{territ: state.Phase.Deploy.Reinforcements},
+		}}, nil
+	case state.Phase.Attack != nil:
+		options := attacks(state, m, player)
+		if len(options) == 0 || b.rng.Intn(3) == 0 {
+			return &Action{EndAttack: &EndPhaseAction{Player: player}}, nil
+		}
+		return &Action{Attack: options[b.rng.Intn(len(options))]}, nil
+	case state.Phase.Advance != nil:
+		from := state.Territs[state.Phase.Advance.From]
+		return &Action{Advance: &MoveAction{
+			Player: player,
+			From:   state.Phase.Advance.From,
+			To:     state.Phase.Advance.To,
+			Troops: uint64(b.rng.Int63n(int64(from.Troops))),
+		}}, nil
+	case state.Phase.Reinforce != nil:
+		var moves []*MoveAction
+		for _, from := range territs {
+			troops := state.Territs[from].Troops
+			if troops <= 1 {
+				continue
+			}
+			for _, neighbour := range m.Territs[from].Neighbours {
+				if state.Owns(player, neighbour.Name) {
+					moves = append(moves, &MoveAction{Player: player, From: from, To: neighbour.Name, Troops: troops - 1})
+				}
+			}
+		}
+		if len(moves) == 0 || b.rng.Intn(2) == 0 {
+			return &Action{EndReinforce: &EndPhaseAction{Player: player}}, nil
+		}
+		return &Action{Reinforce: moves[b.rng.Intn(len(moves))]}, nil
+	}
+	return nil, fmt.Errorf("nothing to do in phase '%s'", state.Phase.Name())
+}
+
+// HeuristicBot deploys to its borders, attacks when the odds favour it and
+// works towards completing regions.
+type HeuristicBot struct{}
+
+// targetRegion returns the region the player is closest to holding, ignoring
+// regions it already holds.
+func (b *HeuristicBot) targetRegion(state *GameState, m *Map, player string) *Region {
+	var names []string
+	for name := range m.Regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var best *Region
+	var bestScore float64
+	for _, name := range names {
+		region := m.Regions[name]
+		owned := 0
+		for _, territ := range region.Territs {
+			if state.Owns(player, territ) {
+				owned += 1
+			}
+		}
+		if owned == 0 || owned == len(region.Territs) {
+			continue
+		}
+		// Prefer regions that are nearly held, then the ones worth more.
+		score := float64(owned)/float64(len(region.Territs)) + float64(region.Bonus)/100
+		if best == nil || score > bestScore {
+			best = region
+			bestScore = score
+		}
+	}
+	return best
+}
+
+func inRegion(region *Region, territ string) bool {
+	if region == nil {
+		return false
+	}
+	for _, name := range region.Territs {
+		if name == territ {
+			return true
+		}
+	}
+	return false
+}
+
+// threat returns how many more enemy troops border the territory than it holds.
+func threat(state *GameState, m *Map, territ string, player string) int64 {
+	var enemies int64
+	for _, enemy := range enemyNeighbours(state, m, territ, player) {
+		// Neutral troops never attack.
+		if state.Territs[enemy].Owner != NeutralOwner {
+			enemies += int64(state.Territs[enemy].Troops)
+		}
+	}
+	return enemies - int64(state.Territs[territ].Troops)
+}
+
+func (b *HeuristicBot) NextAction(state *GameState, m *Map) (*Action, error) {
+	player := state.ActivePlayer
+	territs := ownedTerrits(state, player)
+	region := b.targetRegion(state, m, player)
+	switch {
+	case state.Phase.Draft != nil:
+		// Bid up to the starting troops for territories next to our own, and
+		// otherwise draft like a player who ran out of time.
+		if auction := state.Phase.Draft.Auction; auction != nil {
+			bid := auction.Bid + 1
+			if bid <= state.Settings.StartingTroops && bid <= state.findPlayer(player).Pool {
+				for _, neighbour := range m.Territs[auction.Territory].Neighbours {
+					if state.Owns(player, neighbour.Name) {
+						return &Action{Bid: &BidAction{Player: player, Troops: bid}}, nil
+					}
+				}
+			}
+		}
+		return state.autoDraftAction(m), nil
+	case state.Phase.Placement != nil:
+		return state.autoDraftAction(m), nil
+	case state.Phase.Spoils != nil:
+		return &Action{Spoils: &SpoilsAction{Player: player, Spoils: spoilsSet(state.findPlayer(player).Spoils)}}, nil
+
+	case state.Phase.Deploy != nil:
+		// Reinforce the strongest territory bordering the target region, so
+		// that it can attack, or else the most threatened border.
+		var target string
+		var targetTroops uint64
+		for _, territ := range territs {
+			for _, enemy := range enemyNeighbours(state, m, territ, player) {
+				if inRegion(region, enemy) && (target == "" || state.Territs[territ].Troops > targetTroops) {
+					target = territ
+					targetTroops = state.Territs[territ].Troops
+				}
+			}
+		}
+		if target == "" {
+			var targetThreat int64
+			for _, territ := range territs {
+				if len(enemyNeighbours(state, m, territ, player)) == 0 {
+					continue
+				}
+				if t := threat(state, m, territ, player); target == "" || t > targetThreat {
+					target = territ
+					targetThreat = t
+				}
+			}
+		}
+		if target == "" {
+			target = territs[0]
+		}
+		return &Action{Deploy: &DeployAction{
+			Player:      player,
+			Deployments: map[string]uint64{target: state.Phase.Deploy.Reinforcements},
+		}}, nil
+
+	case state.Phase.Attack != nil:
+		// Only attack with more troops than the defender has, preferring the
+		// target region and then the biggest advantage.
+		var best *AttackAction
+		var bestScore int64
+		for _, attack := range attacks(state, m, player) {
+			attackers := int64(state.Territs[attack.From].Troops) - 1
+			defenders := int64(state.Territs[attack.To].Troops)
+			if attackers <= defenders {
+				continue
+			}
+			score := attackers - defenders
+			if inRegion(region, attack.To) {
+				score += 1000
+			}
+			if best == nil || score > bestScore {
+				best = attack
+				bestScore = score
+			}
+		}
+		if best == nil {
+			return &Action{EndAttack: &EndPhaseAction{Player: player}}, nil
+		}
+		return &Action{Attack: best}, nil
+
+	case state.Phase.Advance != nil:
+		from := state.Phase.Advance.From
+		movable := state.Territs[from].Troops - 1
+		if len(enemyNeighbours(state, m, from, player)) > 0 {
+			// Keep half behind to hold the border.
+			movable /= 2
+		}
+		return &Action{Advance: &MoveAction{
+			Player: player,
+			From:   from,
+			To:     state.Phase.Advance.To,
+			Troops: movable,
+		}}, nil
+
+	case state.Phase.Reinforce != nil:
+		// Move the largest army that isn't on a border to the most threatened
+		// border it can reach.
+		var from string
+		for _, territ := range territs {
+			if len(enemyNeighbours(state, m, territ, player)) > 0 || state.Territs[territ].Troops <= 1 {
+				continue
+			}
+			if from == "" || state.Territs[territ].Troops > state.Territs[from].Troops {
+				from = territ
+			}
+		}
+		if from != "" {
+			var to string
+			var toThreat int64
+			for _, territ := range territs {
+				if len(enemyNeighbours(state, m, territ, player)) == 0 || !m.IsConnected(from, territ, player, state) {
+					continue
+				}
+				if t := threat(state, m, territ, player); to == "" || t > toThreat {
+					to = territ
+					toThreat = t
+				}
+			}
+			if to != "" {
+				return &Action{Reinforce: &MoveAction{
+					Player: player,
+					From:   from,
+					To:     to,
+					Troops: state.Territs[from].Troops - 1,
+				}}, nil
+			}
+		}
+		return &Action{EndReinforce: &EndPhaseAction{Player: player}}, nil
+	}
+	return nil, fmt.Errorf("nothing to do in phase '%s'", state.Phase.Name())
+}
+
+// botMoveDelay is the pause before each bot action, so that watchers can
+// follow along. Configured from flags in main.
+var botMoveDelay = 500 * time.Millisecond
+
+// activeBotLocked returns the bot playing for the active player, or nil if the
+// active player is human.
+func (game *Game) activeBotLocked() Bot {
+	if game.state.Phase.Lobby != nil || game.state.Phase.GameOver != nil {
+		return nil
+	}
+	player := game.state.findPlayer(game.state.ActivePlayer)
+	if player == nil || player.Bot == "" {
+		return nil
+	}
+	if bot, found := game.bots[player.Name]; found {
+		return bot
+	}
+	bot, err := NewBot(player.Bot)
+	if err != nil {
+		log.Printf("no bot for game=%s player=%s: %v", game.id, player.Name, err)
+		return nil
+	}
+	if game.bots == nil {
+		game.bots = make(map[string]Bot)
+	}
+	game.bots[player.Name] = bot
+	return bot
+}
+
+// wakeBotsLocked starts playing for the active player if it is a bot.
+func (game *Game) wakeBotsLocked() {
+	if game.botsRunning || game.activeBotLocked() == nil {
+		return
+	}
+	game.botsRunning = true
+	go game.playBots()
+}
+
+// playBots plays bot actions until a human player is active or the game ends.
+func (game *Game) playBots() {
+	for game.playBotStep() {
+	}
+}
+
+// playBotStep waits, then plays the next action of the active bot. It returns
+// false once no bot can play.
+func (game *Game) playBotStep() (played bool) {
+	time.Sleep(botMoveDelay)
+	game.lock.Lock()
+	defer game.lock.Unlock()
+	defer func() {
+		// This runs on its own goroutine, so a bug must not take down the
+		// server.
+		if r := recover(); r != nil {
+			log.Printf("bot panicked in game=%s: %v", game.id, r)
+			played = false
+		}
+		if !played {
+			game.botsRunning = false
+		}
+	}()
+	return game.playBotActionLocked()
+}
+
+// playBotActionLocked applies the next action of the active bot. If the bot
+// fails to come up with a legal action, a random one is played instead, and
+// failing that, the turn is played the way a timed out turn is, so that the
+// game doesn't stall.
+func (game *Game) playBotActionLocked() bool {
+	bot := game.activeBotLocked()
+	if bot == nil {
+		return false
+	}
+	player := game.state.ActivePlayer
+	events, err := game.applyBotActionLocked(bot)
+	if err != nil {
+		log.Printf("bot failed to play in game=%s player=%s: %v", game.id, player, err)
+		events, err = game.applyBotActionLocked(&RandomBot{rng: rand.New(rand.NewSource(rand.Int63()))})
+	}
+	if err != nil {
+		log.Printf("random fallback failed to play in game=%s player=%s: %v", game.id, player, err)
+		events, err = game.applyActionLocked(game.state.autoPlayAction(game.m))
+	}
+	if err != nil {
+		log.Printf("automatic play failed in game=%s player=%s: %v", game.id, player, err)
+		return false
+	}
+	if err := game.hub.Publish(events, game.fogLocked); err != nil {
+		log.Print("failed to notify watchers:", err)
+	}
+	return true
+}
+
+func (game *Game) applyBotActionLocked(bot Bot) ([]*Event, error) {
+	state := game.state.RedactForPlayer(game.state.ActivePlayer, game.fogLocked(game.state.ActivePlayer))
+	action, err := nextBotAction(bot, state, game.m)
+	if err != nil {
+		return nil, err
+	}
+	return game.applyActionLocked(action)
+}
+
+// nextBotAction asks the bot for its next action, turning a panic into an
+// error so that a buggy bot gets the same fallbacks as a bot that fails.
+func nextBotAction(bot Bot, state *GameState, m *Map) (action *Action, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("bot panicked: %v", r)
+		}
+	}()
+	return bot.NextAction(state, m)
+}
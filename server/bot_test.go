@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBotsPlayLegalMoves(t *testing.T) {
+	for _, draft := range []DraftMode{RandomDraft, ClaimDraft, BidDraft} {
+		t.Run(string(draft), func(t *testing.T) {
+			settings := DefaultGameSettings("hk")
+			settings.Draft = draft
+			playBots(t, settings)
+		})
+	}
+}
+
+// playBots plays a game between a random and a heuristic bot, checking that
+// every action they choose is legal.
+func playBots(t *testing.T, settings GameSettings) {
+	t.Helper()
+	game, err := NewGame("bots", settings, loadTestMap(t), 1, NullStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bots := map[string]Bot{
+		"random bot 1":    &RandomBot{rng: rand.New(rand.NewSource(1))},
+		"heuristic bot 1": &HeuristicBot{},
+	}
+	for _, action := range []*Action{
+		{JoinGame: &JoinGameAction{Player: "random bot 1", Bot: RandomBotKind}},
+		{JoinGame: &JoinGameAction{Player: "heuristic bot 1", Bot: HeuristicBotKind}},
+		{StartGame: &StartGameAction{Player: "random bot 1"}},
+	} {
+		if _, err := game.applyActionLocked(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2000 && game.state.Phase.GameOver == nil; i++ {
+		player := game.state.ActivePlayer
+		state := game.state.RedactForPlayer(player, game.fogLocked(player))
+		action, err := bots[player].NextAction(state, game.m)
+		if err != nil {
+			t.Fatalf("%s: %v", player, err)
+		}
+		// The heuristic bot only attacks with more troops than the defender.
+		if attack := action.Attack; attack != nil && player == "heuristic bot 1" {
+			if game.state.Territs[attack.From].Troops-1 <= game.state.Territs[attack.To].Troops {
+				t.Errorf("attacked %s from %s without an advantage", attack.To, attack.From)
+			}
+		}
+		if _, err := game.applyActionLocked(action); err != nil {
+			t.Fatalf("%s played an illegal move in phase '%s': %v", player, game.state.Phase.Name(), err)
+		}
+	}
+}
+
+func TestNewBot(t *testing.T) {
+	for _, kind := range []string{RandomBotKind, HeuristicBotKind} {
+		if _, err := NewBot(kind); err != nil {
+			t.Errorf("%s: %v", kind, err)
+		}
+	}
+	if _, err := NewBot("genius"); err == nil {
+		t.Error("created an unknown bot")
+	}
+}
+
+// panickingBot is a bot with a bug.
+type panickingBot struct{}
+
+func (panickingBot) NextAction(state *GameState, m *Map) (*Action, error) {
+	panic("bug")
+}
+
+// newBotGame starts a game in which a heuristic bot plays against alice.
+func newBotGame(t *testing.T) *Game {
+	t.Helper()
+	game, err := NewGame("bots", DefaultGameSettings("hk"), loadTestMap(t), 1, NullStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []*Action{
+		{JoinGame: &JoinGameAction{Player: "alice"}},
+		{JoinGame: &JoinGameAction{Player: "heuristic bot 1", Bot: HeuristicBotKind}},
+		{StartGame: &StartGameAction{Player: "alice"}},
+	} {
+		if _, err := game.applyActionLocked(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return game
+}
+
+func TestBuggyBotStillPlays(t *testing.T) {
+	game := newBotGame(t)
+	game.bots = map[string]Bot{"heuristic bot 1": panickingBot{}}
+	for game.state.ActivePlayer != "heuristic bot 1" {
+		if _, err := game.applyActionLocked(game.state.autoPlayAction(game.m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seq := game.state.Seq
+	if !game.playBotActionLocked() {
+		t.Fatal("bot did not play")
+	}
+	if game.state.Seq == seq {
+		t.Error("bot's action produced no events")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// validateMapCommand validates the given map files, or every map in mapsDir if
+// none are given. It prints each problem found and returns the exit status.
+func validateMapCommand(mapsDir string, paths []string) int {
+	if len(paths) == 0 {
+		entries, err := ioutil.ReadDir(mapsDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), mapFileSuffix) {
+				paths = append(paths, filepath.Join(mapsDir, entry.Name()))
+			}
+		}
+	}
+
+	status := 0
+	for _, path := range paths {
+		m, err := LoadMap(path)
+		if err == nil {
+			err = ValidateMap(m)
+		}
+		if err != nil {
+			status = 1
+			if errs, ok := err.(MapErrors); ok {
+				for _, err := range errs {
+					fmt.Printf("%s: %v\n", path, err)
+				}
+			} else {
+				fmt.Printf("%s: %v\n", path, err)
+			}
+		} else {
+			fmt.Printf("%s: ok\n", path)
+		}
+	}
+	return status
+}
+
+// importSVGCommand converts an SVG into a map and writes it as JSON.
+func importSVGCommand(args []string) int {
+	flags := flag.NewFlagSet("import-svg", flag.ExitOnError)
+	name := flags.String("name", "", "display name of the map")
+	assetPath := flags.String("asset-path", "", "asset path of the map")
+	output := flags.String("o", "", "file to write the map to, instead of stdout")
+	tolerance := flags.Float64("tolerance", 2, "maximum distance between the outlines of neighbouring territories")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: malaise import-svg [flags] map.svg")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+	m, err := ImportSVG(f, *tolerance)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flags.Arg(0), err)
+		return 1
+	}
+	m.Name = *name
+	m.AssetPath = *assetPath
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		outFile, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer outFile.Close()
+		out = outFile
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(m); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// Neighbours are only a guess, so point out anything that needs fixing.
+	if err := ValidateMap(m); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: imported map needs editing:\n%v\n", flags.Arg(0), err)
+	}
+	return 0
+}
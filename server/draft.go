@@ -0,0 +1,426 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DraftMode decides how territories are handed out at the start of a game.
+type DraftMode string
+
+const (
+	// RandomDraft deals the territories out at random, with StartingTroops
+	// troops on each.
+	RandomDraft DraftMode = "random"
+	// ClaimDraft has players take turns claiming a territory each.
+	ClaimDraft DraftMode = "claim"
+	// BidDraft has players take turns putting a territory up for auction.
+	// Bids are paid in troops, which are placed on the territory.
+	BidDraft DraftMode = "bid"
+)
+
+// placementTroops is the number of troops placed in each turn of the placement
+// phase.
+const placementTroops = 3
+
+func ParseDraftMode(mode string) (DraftMode, error) {
+	switch DraftMode(mode) {
+	case RandomDraft, ClaimDraft, BidDraft:
+		return DraftMode(mode), nil
+	}
+	return "", fmt.Errorf("unknown draft mode '%s'", mode)
+}
+
+// Drafted returns true if players choose their own territories.
+func (d DraftMode) Drafted() bool {
+	return d == ClaimDraft || d == BidDraft
+}
+
+// DraftPhase is a player's turn to claim a territory, or to bid on one.
+type DraftPhase struct {
+	Mode DraftMode `json:"mode"`
+	// Auction is the territory being bid on, if any. Without one, the player
+	// must put a territory up for auction with an opening bid.
+	Auction *Auction `json:"auction,omitempty"`
+}
+
+type Auction struct {
+	Territory string `json:"territory"`
+	Bid       uint64 `json:"bid"`
+	Bidder    string `json:"bidder"`
+	// Nominator put the territory up for auction. The next auction is opened
+	// by the player after them.
+	Nominator string   `json:"nominator"`
+	Passed    []string `json:"passed"`
+}
+
+// PlacementPhase is a player's turn to place troops from their pool once all
+// territories are drafted.
+type PlacementPhase struct {
+	Troops uint64 `json:"troops"`
+}
+
+type ClaimAction struct {
+	Player    string `json:"player"`
+	Territory string `json:"territory"`
+}
+
+// BidAction opens an auction for the territory, or raises the bid in the open
+// auction. A bid of zero troops passes, leaving the auction for good.
+type BidAction struct {
+	Player    string `json:"player"`
+	Territory string `json:"territory,omitempty"`
+	Troops    uint64 `json:"troops"`
+}
+
+type ClaimedEvent struct {
+	Player    string `json:"player"`
+	Territory string `json:"territory"`
+	Troops    uint64 `json:"troops"`
+}
+
+// drafting returns true until the drafted territories are filled with troops.
+func (g *GameState) drafting() bool {
+	return g.Phase.Draft != nil || g.Phase.Placement != nil
+}
+
+// unclaimedTerrits returns the territories nobody has drafted yet.
+func (g *GameState) unclaimedTerrits() []string {
+	var territs []string
+	for name, territ := range g.Territs {
+		if territ.Owner == UnclaimedOwner {
+			territs = append(territs, name)
+		}
+	}
+	sort.Strings(territs)
+	return territs
+}
+
+// nextPlayerAfter returns the first player after the given one, in turn order,
+// that matches. The given player comes last. It returns nil if no player
+// matches.
+func (g *GameState) nextPlayerAfter(name string, matches func(player *Player) bool) *Player {
+	start := 0
+	for idx := range g.Players {
+		if g.Players[idx].Name == name {
+			start = idx + 1
+		}
+	}
+	for i := range g.Players {
+		player := g.Players[(start+i)%len(g.Players)]
+		if matches(player) {
+			return player
+		}
+	}
+	return nil
+}
+
+func hasPool(player *Player) bool {
+	return player.Pool > 0
+}
+
+// startDraft leaves the territories that aren't held for the neutral owner
+// unclaimed, and gives every player a pool of troops to draft with.
+func (g *GameState) startDraft(m *Map) {
+	territs := g.deployNeutral(m)
+	if g.Settings.NeutralPlayer {
+		// The neutral player doesn't draft, so its share is dealt up front.
+		share := len(territs) / (len(g.Players) + 1)
+		for _, name := range territs[:share] {
+			g.Territs[name] = &TerritoryMut{Owner: NeutralOwner, Troops: g.neutralTroops()}
+		}
+		territs = territs[share:]
+	}
+	for _, name := range territs {
+		g.Territs[name] = &TerritoryMut{Owner: UnclaimedOwner}
+	}
+	// Players get as many troops as they would have if the territories were
+	// dealt.
+	share := (uint64(len(territs)) + uint64(len(g.Players)) - 1) / uint64(len(g.Players))
+	for _, player := range g.Players {
+		player.Pool = g.Settings.StartingTroops * share
+	}
+	g.Phase = Phase{Draft: &DraftPhase{Mode: g.Settings.Draft}}
+	g.calculateStats(m)
+	g.ActivePlayer = g.Players[g.rng.Intn(len(g.Players))].Name
+	g.startTurnClock()
+}
+
+// passDraftTurn gives the next turn of the draft to the player, even if it is
+// the active player again.
+func (g *GameState) passDraftTurn(next *Player) {
+	g.stopTurnClock()
+	g.ActivePlayer = next.Name
+	g.startTurnClock()
+}
+
+func (g *GameState) applyClaimAction(m *Map, claim *ClaimAction) ([]*Event, error) {
+	if claim == nil || g.Phase.Draft.Mode != ClaimDraft {
+		return nil, fmt.Errorf("action does not apply to 'draft' phase")
+	}
+	if g.ActivePlayer != claim.Player {
+		return nil, fmt.Errorf("it is not your turn")
+	}
+	territ, found := g.Territs[claim.Territory]
+	if !found {
+		return nil, fmt.Errorf("territory '%s' does not exist", claim.Territory)
+	}
+	if territ.Owner != UnclaimedOwner {
+		return nil, fmt.Errorf("territory '%s' is already claimed", claim.Territory)
+	}
+	player := g.findPlayer(claim.Player)
+	if player.Pool == 0 {
+		return nil, fmt.Errorf("no troops left to claim territory '%s'", claim.Territory)
+	}
+	territ.Owner = claim.Player
+	territ.Troops = 1
+	player.Pool -= 1
+	events := []*Event{{Claimed: &ClaimedEvent{Player: claim.Player, Territory: claim.Territory, Troops: 1}}}
+	return append(events, g.nextDraftTurn(m, claim.Player)...), nil
+}
+
+func (g *GameState) applyBidAction(m *Map, bid *BidAction) ([]*Event, error) {
+	if bid == nil || g.Phase.Draft.Mode != BidDraft {
+		return nil, fmt.Errorf("action does not apply to 'draft' phase")
+	}
+	if g.ActivePlayer != bid.Player {
+		return nil, fmt.Errorf("it is not your turn")
+	}
+	player := g.findPlayer(bid.Player)
+	var auction Auction
+	if g.Phase.Draft.Auction == nil {
+		territ, found := g.Territs[bid.Territory]
+		if !found {
+			return nil, fmt.Errorf("territory '%s' does not exist", bid.Territory)
+		}
+		if territ.Owner != UnclaimedOwner {
+			return nil, fmt.Errorf("territory '%s' is already claimed", bid.Territory)
+		}
+		if bid.Troops == 0 {
+			return nil, fmt.Errorf("must bid at least 1 troop")
+		}
+		auction = Auction{Territory: bid.Territory, Nominator: bid.Player, Passed: []string{}}
+	} else {
+		auction = Auction(*g.Phase.Draft.Auction)
+		if bid.Territory != "" && bid.Territory != auction.Territory {
+			return nil, fmt.Errorf("territory '%s' is up for auction", auction.Territory)
+		}
+		if bid.Troops != 0 && bid.Troops <= auction.Bid {
+			return nil, fmt.Errorf("must bid more than %d troops", auction.Bid)
+		}
+	}
+	if bid.Troops > player.Pool {
+		return nil, fmt.Errorf("cannot bid %d troops, only %d in your pool", bid.Troops, player.Pool)
+	}
+	if bid.Troops == 0 {
+		auction.Passed = append(append([]string{}, auction.Passed...), bid.Player)
+	} else {
+		auction.Bid = bid.Troops
+		auction.Bidder = bid.Player
+	}
+	events := []*Event{{Bid: &BidAction{Player: bid.Player, Territory: auction.Territory, Troops: bid.Troops}}}
+
+	// Players who can't outbid the highest bid are out of the auction.
+	next := g.nextPlayerAfter(bid.Player, func(other *Player) bool {
+		if other.Name == auction.Bidder || other.Pool <= auction.Bid {
+			return false
+		}
+		for _, passed := range auction.Passed {
+			if passed == other.Name {
+				return false
+			}
+		}
+		return true
+	})
+	if next == nil {
+		// The highest bidder wins, and the troops they bid hold the territory.
+		territ := g.Territs[auction.Territory]
+		territ.Owner = auction.Bidder
+		territ.Troops = auction.Bid
+		g.findPlayer(auction.Bidder).Pool -= auction.Bid
+		events = append(events, &Event{Claimed: &ClaimedEvent{
+			Player:    auction.Bidder,
+			Territory: auction.Territory,
+			Troops:    auction.Bid,
+		}})
+		return append(events, g.nextDraftTurn(m, auction.Nominator)...), nil
+	}
+	oldPhase := g.Phase
+	g.Phase = Phase{Draft: &DraftPhase{Mode: BidDraft, Auction: &auction}}
+	g.passDraftTurn(next)
+	return append(events, &Event{PhaseChanged: &PhaseChangedEvent{
+		OldPlayer:    bid.Player,
+		NewPlayer:    g.ActivePlayer,
+		OldPhase:     oldPhase,
+		NewPhase:     g.Phase,
+		TurnDeadline: g.TurnDeadline,
+	}}), nil
+}
+
+// nextDraftTurn moves on after a territory is claimed: to the next player
+// with troops left to claim with, or to the placement phase once no territory
+// is left or nobody can claim one.
+func (g *GameState) nextDraftTurn(m *Map, after string) []*Event {
+	oldPhase := g.Phase
+	oldPlayer := g.ActivePlayer
+	unclaimed := g.unclaimedTerrits()
+	next := g.nextPlayerAfter(after, hasPool)
+	if len(unclaimed) == 0 || next == nil {
+		// Territories nobody could claim stay neutral.
+		for _, name := range unclaimed {
+			g.Territs[name] = &TerritoryMut{Owner: NeutralOwner, Troops: g.neutralTroops()}
+		}
+		g.nextPlacementTurn(m, after)
+	} else {
+		g.Phase = Phase{Draft: &DraftPhase{Mode: oldPhase.Draft.Mode}}
+		g.passDraftTurn(next)
+	}
+	g.calculateStats(m)
+	events := []*Event{
+		{PhaseChanged: &PhaseChangedEvent{
+			OldPlayer:    oldPlayer,
+			NewPlayer:    g.ActivePlayer,
+			OldPhase:     oldPhase,
+			NewPhase:     g.Phase,
+			TurnDeadline: g.TurnDeadline,
+		}},
+		{StatsChanged: g.statsUpdate()},
+	}
+	if g.Settings.FogOfWar {
+		// The claim changed what the player can see.
+		events = append(events, &Event{Snapshot: g})
+	}
+	return events
+}
+
+// leaveDraft moves the draft on when the player leaves it on their turn. An
+// auction the player takes part in is called off, and the draft moves on from
+// whoever nominated the territory.
+func (g *GameState) leaveDraft(m *Map, name string) {
+	switch {
+	case g.Phase.Placement != nil:
+		if g.ActivePlayer == name {
+			g.nextPlacementTurn(m, name)
+		}
+	case g.Phase.Draft.Auction != nil:
+		auction := g.Phase.Draft.Auction
+		if g.ActivePlayer == name || auction.Bidder == name || auction.Nominator == name {
+			g.nextDraftTurn(m, auction.Nominator)
+		}
+	case g.ActivePlayer == name:
+		g.nextDraftTurn(m, name)
+	}
+}
+
+// nextPlacementTurn gives the next player with troops left in their pool a
+// turn to place them, or starts the first turn of the game once every pool is
+// empty.
+func (g *GameState) nextPlacementTurn(m *Map, after string) {
+	g.calculateStats(m)
+	next := g.nextPlayerAfter(after, func(player *Player) bool {
+		// Players who drafted no territory have nowhere to place troops.
+		return player.Pool > 0 && player.Territories > 0
+	})
+	if next != nil {
+		g.Phase = Phase{Placement: &PlacementPhase{Troops: min(next.Pool, placementTroops)}}
+		g.passDraftTurn(next)
+		return
+	}
+	g.stopTurnClock()
+	for _, player := range g.Players {
+		player.Pool = 0
+	}
+	g.Phase = Phase{Deploy: &DeployPhase{}}
+	if g.calculateStats(m) {
+		// One side drafted every territory.
+		for _, player := range g.Players {
+			if !player.Eliminated {
+				g.Phase = g.gameOver(player.Name)
+				break
+			}
+		}
+		return
+	}
+	g.startFirstTurn(m)
+}
+
+func (g *GameState) applyPlaceAction(m *Map, place *DeployAction) ([]*Event, error) {
+	if place == nil {
+		return nil, fmt.Errorf("action does not apply to 'placement' phase")
+	}
+	if g.ActivePlayer != place.Player {
+		return nil, fmt.Errorf("it is not your turn")
+	}
+	var total uint64
+	for territ, troops := range place.Deployments {
+		territMut, found := g.Territs[territ]
+		if !found {
+			return nil, fmt.Errorf("territory '%s' does not exist", territ)
+		}
+		if territMut.Owner != place.Player {
+			return nil, fmt.Errorf("territory '%s' does not belong to you", territ)
+		}
+		// Check each value on its own so that the total can't wrap around.
+		if troops > g.Phase.Placement.Troops-total {
+			return nil, fmt.Errorf("must place exactly %d troops", g.Phase.Placement.Troops)
+		}
+		total += troops
+	}
+	if total != g.Phase.Placement.Troops {
+		return nil, fmt.Errorf("must place exactly %d troops", g.Phase.Placement.Troops)
+	}
+	for territ, troops := range place.Deployments {
+		g.Territs[territ].Troops += troops
+	}
+	g.findPlayer(place.Player).Pool -= total
+
+	oldPhase := g.Phase
+	g.nextPlacementTurn(m, place.Player)
+	return []*Event{
+		{Placed: place},
+		{PhaseChanged: &PhaseChangedEvent{
+			OldPlayer:    place.Player,
+			NewPlayer:    g.ActivePlayer,
+			OldPhase:     oldPhase,
+			NewPhase:     g.Phase,
+			TurnDeadline: g.TurnDeadline,
+		}},
+		{StatsChanged: g.statsUpdate()},
+	}, nil
+}
+
+// autoDraftAction returns the action played for the active player during the
+// draft when they run out of time. It claims or bids on territories next to
+// the player's own, never raises a bid, and places troops along the player's
+// borders.
+func (g *GameState) autoDraftAction(m *Map) *Action {
+	player := g.ActivePlayer
+	if g.Phase.Placement != nil {
+		return &Action{Place: &DeployAction{
+			Player:      player,
+			Deployments: g.borderDeployments(m, player, g.Phase.Placement.Troops),
+		}}
+	}
+	if g.Phase.Draft.Auction != nil {
+		return &Action{Bid: &BidAction{Player: player}}
+	}
+	territ := g.draftChoice(m, player)
+	if g.Phase.Draft.Mode == BidDraft {
+		return &Action{Bid: &BidAction{Player: player, Territory: territ, Troops: 1}}
+	}
+	return &Action{Claim: &ClaimAction{Player: player, Territory: territ}}
+}
+
+// draftChoice returns an unclaimed territory, preferring one that borders the
+// player's territories.
+func (g *GameState) draftChoice(m *Map, player string) string {
+	unclaimed := g.unclaimedTerrits()
+	for _, territ := range unclaimed {
+		for _, neighbour := range m.Territs[territ].Neighbours {
+			if g.Owns(player, neighbour.Name) {
+				return territ
+			}
+		}
+	}
+	return unclaimed[0]
+}
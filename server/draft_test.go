@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// draftToPlacement plays a claim draft until the placement phase.
+func draftToPlacement(t *testing.T, m *Map, state *GameState) {
+	t.Helper()
+	for state.Phase.Draft != nil {
+		mustApply(t, state, m, state.autoDraftAction(m))
+	}
+	if state.Phase.Placement == nil {
+		t.Fatalf("draft ended in phase '%s'", state.Phase.Name())
+	}
+}
+
+func TestClaimDraft(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.Draft = ClaimDraft
+	state := newTestState(t, m, settings, "alice", "bob")
+	if state.Phase.Draft == nil {
+		t.Fatalf("game started in phase '%s'", state.Phase.Name())
+	}
+	if _, err := state.ApplyAction(m, &Action{Claim: &ClaimAction{Player: state.ActivePlayer, Territory: "nowhere"}}, testTime); err == nil {
+		t.Error("claimed a territory that does not exist")
+	}
+
+	draftToPlacement(t, m, state)
+	if unclaimed := state.unclaimedTerrits(); len(unclaimed) != 0 {
+		t.Errorf("territories left unclaimed: %v", unclaimed)
+	}
+	for state.Phase.Placement != nil {
+		mustApply(t, state, m, state.autoDraftAction(m))
+	}
+	if state.Phase.Deploy == nil || state.Turn != 1 {
+		t.Fatalf("placement ended in phase '%s' turn %d", state.Phase.Name(), state.Turn)
+	}
+	// Every player places as many troops as if the territories were dealt.
+	for _, player := range state.Players {
+		if player.Pool != 0 {
+			t.Errorf("player '%s' has %d troops left in their pool", player.Name, player.Pool)
+		}
+		if player.Troops != uint64(len(m.Territs)/2)*settings.StartingTroops {
+			t.Errorf("player '%s' has %d troops", player.Name, player.Troops)
+		}
+	}
+}
+
+func TestBidDraftWinnerHoldsTerritoryWithBid(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.Draft = BidDraft
+	state := newTestState(t, m, settings, "alice", "bob")
+	opener := state.ActivePlayer
+	territ := state.unclaimedTerrits()[0]
+	mustApply(t, state, m, &Action{Bid: &BidAction{Player: opener, Territory: territ, Troops: 1}})
+	other := state.ActivePlayer
+	if other == opener {
+		t.Fatal("bidding did not pass to the other player")
+	}
+	mustApply(t, state, m, &Action{Bid: &BidAction{Player: other, Troops: 4}})
+	mustApply(t, state, m, &Action{Bid: &BidAction{Player: opener}})
+	if owner := state.Territs[territ].Owner; owner != other {
+		t.Errorf("territory went to '%s'", owner)
+	}
+	if troops := state.Territs[territ].Troops; troops != 4 {
+		t.Errorf("territory holds %d troops", troops)
+	}
+	if state.Phase.Draft == nil || state.Phase.Draft.Auction != nil {
+		t.Errorf("auction did not close: %+v", state.Phase)
+	}
+}
+
+func TestPlacementRejectsWrappingTotal(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.Draft = ClaimDraft
+	state := newTestState(t, m, settings, "alice", "bob")
+	draftToPlacement(t, m, state)
+	player := state.ActivePlayer
+	territs := ownedTerrits(state, player)
+	_, err := state.ApplyAction(m, &Action{Place: &DeployAction{
+		Player: player,
+		Deployments: map[string]uint64{
+			territs[0]: math.MaxUint64 - 4,
+			territs[1]: state.Phase.Placement.Troops + 5,
+		},
+	}}, testTime)
+	if err == nil {
+		t.Fatal("placement that wraps around was accepted")
+	}
+	if troops := state.Territs[territs[0]].Troops; troops != 1 {
+		t.Errorf("troops changed to %d", troops)
+	}
+}
+
+func TestDraftCannotClaimNeutralTerritory(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.Draft = ClaimDraft
+	state := newTestState(t, m, settings, "alice", "bob")
+	// A neutral territory without troops is still not up for grabs.
+	territ := state.unclaimedTerrits()[0]
+	state.Territs[territ] = &TerritoryMut{Owner: NeutralOwner}
+	for _, name := range state.unclaimedTerrits() {
+		if name == territ {
+			t.Errorf("neutral territory '%s' is unclaimed", territ)
+		}
+	}
+	if _, err := state.ApplyAction(m, &Action{Claim: &ClaimAction{Player: state.ActivePlayer, Territory: territ}}, testTime); err == nil {
+		t.Errorf("claimed neutral territory '%s'", territ)
+	}
+}
+
+func TestResignDuringDraft(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.Draft = BidDraft
+	state := newTestState(t, m, settings, "alice", "bob", "carol")
+	opener := state.ActivePlayer
+	mustApply(t, state, m, &Action{Bid: &BidAction{Player: opener, Territory: state.unclaimedTerrits()[0], Troops: 1}})
+	mustApply(t, state, m, &Action{Resign: &ResignAction{Player: opener}})
+	if state.Phase.Draft == nil || state.Phase.Draft.Auction != nil {
+		t.Fatalf("auction was not called off: %+v", state.Phase)
+	}
+	if state.ActivePlayer == opener {
+		t.Error("resigned player kept the turn")
+	}
+	if player := state.findPlayer(opener); !player.Eliminated || player.Pool != 0 {
+		t.Errorf("resigned player is still in the draft: %+v", player)
+	}
+
+	mustApply(t, state, m, &Action{Resign: &ResignAction{Player: state.ActivePlayer}})
+	if state.Phase.GameOver == nil {
+		t.Errorf("expected the game to be over, got phase '%s'", state.Phase.Name())
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+// Fog is what a player can see in a game played with fog of war: the
+// territories they and their teammates own and the ones adjacent to them. A
+// nil Fog sees everything.
+type Fog struct {
+	visible map[string]bool
+	allies  map[string]bool
+}
+
+// FogFor returns what the player can currently see, or nil if the game has no
+// fog of war. Everything is revealed once the game is over.
+func (g *GameState) FogFor(m *Map, player string) *Fog {
+	if !g.Settings.FogOfWar || g.Phase.Lobby != nil || g.Phase.GameOver != nil {
+		return nil
+	}
+	fog := &Fog{visible: make(map[string]bool), allies: make(map[string]bool)}
+	for _, other := range g.Players {
+		if player != NeutralOwner && g.allied(player, other.Name) {
+			fog.allies[other.Name] = true
+		}
+	}
+	var owned []string
+	for name, territ := range g.Territs {
+		if fog.allies[territ.Owner] {
+			owned = append(owned, name)
+			fog.visible[name] = true
+		}
+	}
+	for name := range g.Territs {
+		if fog.visible[name] {
+			continue
+		}
+		for _, from := range owned {
+			if m.IsAdjacent(from, name) {
+				fog.visible[name] = true
+				break
+			}
+		}
+	}
+	return fog
+}
+
+// Sees returns true if the exact troops in the territory are visible.
+func (f *Fog) Sees(territ string) bool {
+	return f == nil || f.visible[territ]
+}
+
+// SeesTroopsOf returns true if the total troops of all the players are
+// visible, which is only the case for the player and their teammates.
+func (f *Fog) SeesTroopsOf(players ...string) bool {
+	if f == nil {
+		return true
+	}
+	for _, player := range players {
+		if !f.allies[player] {
+			return false
+		}
+	}
+	return true
+}
+
+// redactDeploy hides deployments to territories the player can't see.
+func (f *Fog) redactDeploy(deploy *DeployAction) *DeployAction {
+	redacted := DeployAction(*deploy)
+	redacted.Deployments = make(map[string]uint64)
+	for territ, troops := range deploy.Deployments {
+		if f.Sees(territ) {
+			redacted.Deployments[territ] = troops
+		}
+	}
+	return &redacted
+}
+
+// redactAttack hides the dice and losses of each side of the attack that the
+// player can't see.
+func (f *Fog) redactAttack(attack *AttackEvent) *AttackEvent {
+	redacted := AttackEvent(*attack)
+	redacted.AttackRoll = *f.redactRoll(&attack.AttackRoll, attack)
+	if attack.Rolls != nil {
+		redacted.Rolls = make([]*AttackRoll, len(attack.Rolls))
+		for i, roll := range attack.Rolls {
+			redacted.Rolls[i] = f.redactRoll(roll, attack)
+		}
+	}
+	return &redacted
+}
+
+func (f *Fog) redactRoll(roll *AttackRoll, attack *AttackEvent) *AttackRoll {
+	redacted := AttackRoll(*roll)
+	if !f.Sees(attack.From) {
+		redacted.AttackerDice = []int{}
+		redacted.AttackerLosses = 0
+	}
+	if !f.Sees(attack.To) {
+		redacted.DefenderDice = []int{}
+		redacted.DefenderLosses = 0
+	}
+	return &redacted
+}
+
+// redactMove hides the troops moved unless the player can see either end, in
+// which case the move is needed to keep that end's count right.
+func (f *Fog) redactMove(move *MoveAction) *MoveAction {
+	if f.Sees(move.From) || f.Sees(move.To) {
+		return move
+	}
+	redacted := MoveAction(*move)
+	redacted.Troops = 0
+	return &redacted
+}
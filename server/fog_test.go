@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFogHidesDistantTroops(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.FogOfWar = true
+	state := newTestState(t, m, settings, "alice", "bob")
+	// Leave alice a single territory so that most of the map is out of sight.
+	for _, territ := range ownedTerrits(state, "alice")[1:] {
+		state.Territs[territ].Owner = "bob"
+	}
+
+	fog := state.FogFor(m, "alice")
+	redacted := state.RedactForPlayer("alice", fog)
+	hidden := 0
+	for name, territ := range state.Territs {
+		seen := territ.Owner == "alice"
+		for _, neighbour := range m.Territs[name].Neighbours {
+			if state.Territs[neighbour.Name].Owner == "alice" {
+				seen = true
+			}
+		}
+		redactedTerrit := redacted.Territs[name]
+		if redactedTerrit.Owner != territ.Owner {
+			t.Errorf("%s: owner changed to %s", name, redactedTerrit.Owner)
+		}
+		if seen && (redactedTerrit.Hidden || redactedTerrit.Troops != territ.Troops) {
+			t.Errorf("%s: visible troops were hidden", name)
+		}
+		if !seen {
+			hidden += 1
+			if !redactedTerrit.Hidden || redactedTerrit.Troops != 0 {
+				t.Errorf("%s: fogged troops were shown", name)
+			}
+		}
+	}
+	if hidden == 0 {
+		t.Error("expected some territories to be fogged")
+	}
+	for _, player := range redacted.Players {
+		if player.Name == "bob" && player.Troops != 0 {
+			t.Errorf("bob's total troops were shown")
+		}
+	}
+	if state.FogFor(m, "alice") == nil {
+		t.Error("expected fog during the game")
+	}
+	state.Settings.FogOfWar = false
+	if state.FogFor(m, "alice") != nil {
+		t.Error("expected no fog without fog of war")
+	}
+}
+
+func TestFogRedactsEvents(t *testing.T) {
+	fog := &Fog{visible: map[string]bool{"seen": true}}
+	deploy := Event{Deploy: &DeployAction{Player: "bob", Deployments: map[string]uint64{"seen": 2, "hidden": 3}}}
+	redacted := deploy.RedactForPlayer("alice", fog)
+	if len(redacted.Deploy.Deployments) != 1 || redacted.Deploy.Deployments["seen"] != 2 {
+		t.Errorf("expected only the visible deployment, got %v", redacted.Deploy.Deployments)
+	}
+
+	attack := Event{Attack: &AttackEvent{
+		AttackAction: AttackAction{Player: "bob", From: "hidden", To: "seen"},
+		AttackRoll: AttackRoll{
+			AttackerDice:   []int{6, 5, 4},
+			DefenderDice:   []int{3, 2},
+			AttackerLosses: 0,
+			DefenderLosses: 2,
+		},
+	}}
+	redacted = attack.RedactForPlayer("alice", fog)
+	if len(redacted.Attack.AttackerDice) != 0 || len(redacted.Attack.DefenderDice) != 2 || redacted.Attack.DefenderLosses != 2 {
+		t.Errorf("expected only the defender's side, got %+v", redacted.Attack)
+	}
+	if len(attack.Attack.AttackerDice) != 3 {
+		t.Error("redacting changed the original event")
+	}
+
+	// Moves that either end can see are kept whole.
+	move := Event{Reinforce: &MoveAction{Player: "bob", From: "hidden", To: "other", Troops: 4}}
+	if troops := move.RedactForPlayer("alice", fog).Reinforce.Troops; troops != 0 {
+		t.Errorf("expected a hidden move, got %d troops", troops)
+	}
+	move.Reinforce.To = "seen"
+	if troops := move.RedactForPlayer("alice", fog).Reinforce.Troops; troops != 4 {
+		t.Errorf("expected a visible move, got %d troops", troops)
+	}
+}
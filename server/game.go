@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"time"
 )
 
 type Action struct {
@@ -12,14 +13,128 @@ type Action struct {
 	Spoils       *SpoilsAction    `json:"spoils,omitempty"`
 	Deploy       *DeployAction    `json:"deploy,omitempty"`
 	Attack       *AttackAction    `json:"attack,omitempty"`
+	Blitz        *BlitzAction     `json:"blitz,omitempty"`
 	EndAttack    *EndPhaseAction  `json:"end_attack,omitempty"`
 	Advance      *MoveAction      `json:"advance,omitempty"`
 	Reinforce    *MoveAction      `json:"reinforce,omitempty"`
 	EndReinforce *EndPhaseAction  `json:"end_reinforce,omitempty"`
+	Timeout      *TimeoutAction   `json:"timeout,omitempty"`
+	Resign       *ResignAction    `json:"resign,omitempty"`
+	Kick         *KickAction      `json:"kick,omitempty"`
+	ChooseTeam   *TeamAction      `json:"choose_team,omitempty"`
+	Claim        *ClaimAction     `json:"claim,omitempty"`
+	Bid          *BidAction       `json:"bid,omitempty"`
+	Place        *DeployAction    `json:"place,omitempty"`
+}
+
+// playerFields returns the player field of every sub-action that is set.
+func (a *Action) playerFields() []*string {
+	var fields []*string
+	if a.JoinGame != nil {
+		fields = append(fields, &a.JoinGame.Player)
+	}
+	if a.StartGame != nil {
+		fields = append(fields, &a.StartGame.Player)
+	}
+	if a.Spoils != nil {
+		fields = append(fields, &a.Spoils.Player)
+	}
+	if a.Deploy != nil {
+		fields = append(fields, &a.Deploy.Player)
+	}
+	if a.Attack != nil {
+		fields = append(fields, &a.Attack.Player)
+	}
+	if a.Blitz != nil {
+		fields = append(fields, &a.Blitz.Player)
+	}
+	if a.EndAttack != nil {
+		fields = append(fields, &a.EndAttack.Player)
+	}
+	if a.Advance != nil {
+		fields = append(fields, &a.Advance.Player)
+	}
+	if a.Reinforce != nil {
+		fields = append(fields, &a.Reinforce.Player)
+	}
+	if a.EndReinforce != nil {
+		fields = append(fields, &a.EndReinforce.Player)
+	}
+	if a.Timeout != nil {
+		fields = append(fields, &a.Timeout.Player)
+	}
+	if a.Resign != nil {
+		fields = append(fields, &a.Resign.Player)
+	}
+	if a.Kick != nil {
+		fields = append(fields, &a.Kick.Player)
+	}
+	if a.ChooseTeam != nil {
+		fields = append(fields, &a.ChooseTeam.Player)
+	}
+	if a.Claim != nil {
+		fields = append(fields, &a.Claim.Player)
+	}
+	if a.Bid != nil {
+		fields = append(fields, &a.Bid.Player)
+	}
+	if a.Place != nil {
+		fields = append(fields, &a.Place.Player)
+	}
+	return fields
+}
+
+// player returns the name of the player performing the action.
+func (a *Action) player() string {
+	fields := a.playerFields()
+	if len(fields) == 0 {
+		return ""
+	}
+	return *fields[0]
+}
+
+// ActAs makes the action act on behalf of the given player. Sub-actions that
+// leave the player empty are filled in, and any sub-action naming a different
+// player is rejected.
+func (a *Action) ActAs(player string) error {
+	if player == "" {
+		return fmt.Errorf("cannot act without a player name")
+	}
+	for _, field := range a.playerFields() {
+		if *field == "" {
+			*field = player
+		} else if *field != player {
+			return fmt.Errorf("cannot act as player '%s'", *field)
+		}
+	}
+	return nil
+}
+
+// RedactForPlayer hides the troops in another player's action that the fog
+// hides from the player.
+func (a Action) RedactForPlayer(playerName string, fog *Fog) *Action {
+	if fog == nil || a.player() == playerName {
+		return &a
+	}
+	if a.Deploy != nil {
+		a.Deploy = fog.redactDeploy(a.Deploy)
+	}
+	if a.Place != nil {
+		a.Place = fog.redactDeploy(a.Place)
+	}
+	if a.Advance != nil {
+		a.Advance = fog.redactMove(a.Advance)
+	}
+	if a.Reinforce != nil {
+		a.Reinforce = fog.redactMove(a.Reinforce)
+	}
+	return &a
 }
 
 type JoinGameAction struct {
 	Player string `json:"player"`
+	// Bot is the kind of bot that plays for the player, if any.
+	Bot string `json:"bot,omitempty"`
 }
 
 type StartGameAction struct {
@@ -42,10 +157,50 @@ type AttackAction struct {
 	To     string `json:"to"`
 }
 
+// BlitzAction keeps attacking until the target is conquered, the attacking
+// territory is down to MinTroops, or MaxRolls rolls were made. A zero MaxRolls
+// means no limit.
+type BlitzAction struct {
+	AttackAction
+	MinTroops uint64 `json:"min_troops,omitempty"`
+	MaxRolls  uint64 `json:"max_rolls,omitempty"`
+	// Detail asks for the dice of every roll.
+	Detail bool `json:"detail,omitempty"`
+}
+
 type EndPhaseAction struct {
 	Player string `json:"player"`
 }
 
+// TimeoutAction is applied by the server when a player's turn runs out of
+// time. The rest of the turn is played for them.
+type TimeoutAction struct {
+	Player string `json:"player"`
+}
+
+// ResignAction takes the player out of the game. Their territories go to the
+// heir, or become neutral if there is no heir.
+type ResignAction struct {
+	Player string `json:"player"`
+	Heir   string `json:"heir,omitempty"`
+}
+
+// KickAction lets the admin take another player out of the game, as if they
+// had resigned.
+type KickAction struct {
+	Player string `json:"player"`
+	Target string `json:"target"`
+	Heir   string `json:"heir,omitempty"`
+}
+
+// TeamAction puts a player on a team in the lobby, or on no team if Team is
+// empty. Only the admin may choose the team of another player, e.g. a bot.
+type TeamAction struct {
+	Player string `json:"player"`
+	Target string `json:"target,omitempty"`
+	Team   string `json:"team"`
+}
+
 type MoveAction struct {
 	Player string `json:"player"`
 	From   string `json:"from"`
@@ -54,6 +209,8 @@ type MoveAction struct {
 }
 
 type Event struct {
+	// Seq increases by one with every event in a game.
+	Seq          uint64             `json:"seq"`
 	PlayerJoined *Player            `json:"player_joined,omitempty"`
 	Deploy       *DeployAction      `json:"deploy,omitempty"`
 	Attack       *AttackEvent       `json:"attack,omitempty"`
@@ -61,41 +218,90 @@ type Event struct {
 	Reinforce    *MoveAction        `json:"reinforce,omitempty"`
 	PhaseChanged *PhaseChangedEvent `json:"phase_changed,omitempty"`
 	StatsChanged *StatsChangedEvent `json:"stats_changed,omitempty"`
+	Timeout      *TimeoutEvent      `json:"timeout,omitempty"`
+	Resigned     *ResignedEvent     `json:"resigned,omitempty"`
+	TeamChosen   *TeamAction        `json:"team_chosen,omitempty"`
+	Claimed      *ClaimedEvent      `json:"claimed,omitempty"`
+	Bid          *BidAction         `json:"bid,omitempty"`
+	Placed       *DeployAction      `json:"placed,omitempty"`
 	Snapshot     *GameState         `json:"snapshot,omitempty"`
 }
 
-func (e Event) RedactForPlayer(playerName string) *Event {
+// RedactForPlayer hides what the player may not see. With fog of war, that
+// includes troops in territories outside the fog.
+func (e Event) RedactForPlayer(playerName string, fog *Fog) *Event {
 	redactedEvent := Event(e)
 	if e.Snapshot != nil {
-		redactedEvent.Snapshot = e.Snapshot.RedactForPlayer(playerName)
+		redactedEvent.Snapshot = e.Snapshot.RedactForPlayer(playerName, fog)
 	} else if e.StatsChanged != nil {
-		redactedEvent.StatsChanged = e.StatsChanged.RedactForPlayer(playerName)
+		redactedEvent.StatsChanged = e.StatsChanged.RedactForPlayer(playerName, fog)
+	} else if fog != nil {
+		switch {
+		case e.Deploy != nil:
+			redactedEvent.Deploy = fog.redactDeploy(e.Deploy)
+		case e.Placed != nil:
+			redactedEvent.Placed = fog.redactDeploy(e.Placed)
+		case e.Attack != nil:
+			redactedEvent.Attack = fog.redactAttack(e.Attack)
+		case e.Advance != nil:
+			redactedEvent.Advance = fog.redactMove(e.Advance)
+		case e.Reinforce != nil:
+			redactedEvent.Reinforce = fog.redactMove(e.Reinforce)
+		}
 	}
 	return &redactedEvent
 }
 
 type AttackEvent struct {
 	AttackAction
-	Defender       string `json:"defender"`
+	Defender string `json:"defender"`
+	AttackRoll
+	Conquered bool `json:"conquered"`
+	// Blitz attacks add up the losses of all their rolls, and leave the dice
+	// empty since no single roll stands for the whole attack. The dice of
+	// each roll are only included if asked to.
+	Blitz     bool          `json:"blitz,omitempty"`
+	RollCount uint64        `json:"roll_count,omitempty"`
+	Rolls     []*AttackRoll `json:"rolls,omitempty"`
+}
+
+type AttackRoll struct {
 	AttackerDice   []int  `json:"attacker_dice"`
 	DefenderDice   []int  `json:"defender_dice"`
 	AttackerLosses uint64 `json:"attacker_losses"`
 	DefenderLosses uint64 `json:"defender_losses"`
-	Conquered      bool   `json:"conquered"`
 }
 
 type PhaseChangedEvent struct {
-	OldPlayer string `json:"old_player"`
-	NewPlayer string `json:"new_player"`
-	OldPhase  Phase  `json:"old_phase"`
-	NewPhase  Phase  `json:"new_phase"`
+	OldPlayer    string     `json:"old_player"`
+	NewPlayer    string     `json:"new_player"`
+	OldPhase     Phase      `json:"old_phase"`
+	NewPhase     Phase      `json:"new_phase"`
+	TurnDeadline *time.Time `json:"turn_deadline,omitempty"`
+}
+
+type ResignedEvent struct {
+	Player string `json:"player"`
+	Heir   string `json:"heir,omitempty"`
+	Kicked bool   `json:"kicked"`
+}
+
+type TimeoutEvent struct {
+	Player string `json:"player"`
+	// Abandoned is true if the player timed out too many turns in a row.
+	Abandoned bool `json:"abandoned"`
+	// Passed is true if the turn couldn't be played automatically and was
+	// passed on without playing it.
+	Passed bool `json:"passed,omitempty"`
 }
 
 type StatsChangedEvent struct {
-	Updates map[string]*StatsUpdate `json:"updates"`
+	Updates         map[string]*StatsUpdate `json:"updates"`
+	Teams           map[string]*TeamStats   `json:"teams,omitempty"`
+	NextSpoilsBonus uint64                  `json:"next_spoils_bonus,omitempty"`
 }
 
-func (e StatsChangedEvent) RedactForPlayer(playerName string) *StatsChangedEvent {
+func (e StatsChangedEvent) RedactForPlayer(playerName string, fog *Fog) *StatsChangedEvent {
 	redactedSpoil := &Spoil{
 		Name:  "???",
 		Color: "???",
@@ -107,6 +313,9 @@ func (e StatsChangedEvent) RedactForPlayer(playerName string) *StatsChangedEvent
 			redactedEvent.Updates[key] = value
 		} else {
 			redacted := StatsUpdate(*value)
+			if !fog.SeesTroopsOf(key) {
+				redacted.Troops = 0
+			}
 			redacted.Spoils = []*Spoil{}
 			for range value.Spoils {
 				redacted.Spoils = append(redacted.Spoils, redactedSpoil)
@@ -114,6 +323,16 @@ func (e StatsChangedEvent) RedactForPlayer(playerName string) *StatsChangedEvent
 			redactedEvent.Updates[key] = &redacted
 		}
 	}
+	if fog != nil && e.Teams != nil {
+		redactedEvent.Teams = make(map[string]*TeamStats)
+		for key, value := range e.Teams {
+			redacted := TeamStats(*value)
+			if !fog.SeesTroopsOf(value.Players...) {
+				redacted.Troops = 0
+			}
+			redactedEvent.Teams[key] = &redacted
+		}
+	}
 	return &redactedEvent
 }
 
@@ -123,11 +342,31 @@ type StatsUpdate struct {
 	Reinforcements uint64   `json:"reinforcements"`
 	Eliminated     bool     `json:"eliminated"`
 	Spoils         []*Spoil `json:"spoils"`
+	Pool           uint64   `json:"pool,omitempty"`
+}
+
+// TeamStats adds up the stats of the players on a team.
+type TeamStats struct {
+	Players        []string `json:"players"`
+	Territories    uint64   `json:"territories"`
+	Troops         uint64   `json:"troops"`
+	Reinforcements uint64   `json:"reinforcements"`
+	Eliminated     bool     `json:"eliminated"`
 }
 
+// NeutralOwner owns territories that belong to no player. It never takes a
+// turn, and only ever loses territories, so it can't win or be eliminated.
+// Usernames can't contain parentheses, so it never clashes with a player.
+const NeutralOwner = "(neutral)"
+
+// UnclaimedOwner owns the territories nobody has drafted yet.
+const UnclaimedOwner = ""
+
 type TerritoryMut struct {
 	Owner  string `json:"owner"`
 	Troops uint64 `json:"troops"`
+	// Hidden is set when fog of war hides the troops from the player.
+	Hidden bool `json:"hidden,omitempty"`
 }
 
 type Spoil struct {
@@ -143,6 +382,17 @@ type Player struct {
 	Troops         uint64   `json:"troops"`
 	Territories    uint64   `json:"territories"`
 	Spoils         []*Spoil `json:"spoils"`
+	Bot            string   `json:"bot,omitempty"`
+	// Team is empty for players who play alone.
+	Team string `json:"team,omitempty"`
+	// Pool is the troops the player has left to draft with.
+	Pool uint64 `json:"pool,omitempty"`
+	// TimeBank is the time left to use once a turn's time limit runs out.
+	TimeBank time.Duration `json:"time_bank,omitempty"`
+	// Timeouts counts the player's turns in a row that timed out.
+	Timeouts  int  `json:"timeouts,omitempty"`
+	Abandoned bool `json:"abandoned,omitempty"`
+	Resigned  bool `json:"resigned,omitempty"`
 }
 
 func (p *Player) HasSpoils() bool {
@@ -164,6 +414,8 @@ func (p *Player) HasSpoils() bool {
 
 type Phase struct {
 	Lobby     *LobbyPhase     `json:"lobby,omitempty"`
+	Draft     *DraftPhase     `json:"draft,omitempty"`
+	Placement *PlacementPhase `json:"placement,omitempty"`
 	Spoils    *SpoilsPhase    `json:"spoils,omitempty"`
 	Deploy    *DeployPhase    `json:"deploy,omitempty"`
 	Attack    *AttackPhase    `json:"attack,omitempty"`
@@ -172,6 +424,31 @@ type Phase struct {
 	GameOver  *GameOverPhase  `json:"game_over,omitempty"`
 }
 
+// Name returns the JSON name of the current phase.
+func (p *Phase) Name() string {
+	switch {
+	case p.Lobby != nil:
+		return "lobby"
+	case p.Draft != nil:
+		return "draft"
+	case p.Placement != nil:
+		return "placement"
+	case p.Spoils != nil:
+		return "spoils"
+	case p.Deploy != nil:
+		return "deploy"
+	case p.Attack != nil:
+		return "attack"
+	case p.Advance != nil:
+		return "advance"
+	case p.Reinforce != nil:
+		return "reinforce"
+	case p.GameOver != nil:
+		return "game_over"
+	}
+	return ""
+}
+
 type LobbyPhase struct{}
 
 type SpoilsPhase struct {
@@ -200,18 +477,46 @@ type ReinforcePhase struct {
 
 type GameOverPhase struct {
 	Winner string `json:"winner"`
+	// In team games, the whole team wins, including eliminated teammates.
+	Team    string   `json:"team,omitempty"`
+	Winners []string `json:"winners,omitempty"`
+}
+
+// Random is the source of randomness for dice, spoils and turn order.
+type Random interface {
+	Intn(n int) int
+	Shuffle(n int, swap func(i int, j int))
+}
+
+// RandomSource creates the Random used to apply a single action.
+type RandomSource func(seed int64) Random
+
+func NewSeededRandom(seed int64) Random {
+	return rand.New(rand.NewSource(seed))
 }
 
 type GameState struct {
-	Phase        Phase                    `json:"phase"`
-	ActivePlayer string                   `json:"active_player"`
-	Players      []*Player                `json:"players"`
-	Territs      map[string]*TerritoryMut `json:"territs"`
-	Map          string                   `json:"map"`
-	spoilPool    []*Spoil
+	Phase           Phase                    `json:"phase"`
+	ActivePlayer    string                   `json:"active_player"`
+	Players         []*Player                `json:"players"`
+	Territs         map[string]*TerritoryMut `json:"territs"`
+	Map             string                   `json:"map"`
+	Settings        GameSettings             `json:"settings"`
+	SetsCashed      uint64                   `json:"sets_cashed,omitempty"`
+	NextSpoilsBonus uint64                   `json:"next_spoils_bonus,omitempty"`
+	Turn            uint64                   `json:"turn"`
+	Seq             uint64                   `json:"seq"`
+	TurnDeadline    *time.Time               `json:"turn_deadline,omitempty"`
+	spoilPool       []*Spoil
+	seed            int64
+	actions         uint64
+	randomSource    RandomSource
+	rng             Random
+	now             time.Time
+	timingOut       bool
 }
 
-func (g GameState) RedactForPlayer(playerName string) *GameState {
+func (g GameState) RedactForPlayer(playerName string, fog *Fog) *GameState {
 	redactedSpoil := &Spoil{
 		Name:  "???",
 		Color: "???",
@@ -223,6 +528,9 @@ func (g GameState) RedactForPlayer(playerName string) *GameState {
 			redactedPlayers = append(redactedPlayers, player)
 		} else {
 			redacted := Player(*player)
+			if !fog.SeesTroopsOf(player.Name) {
+				redacted.Troops = 0
+			}
 			redacted.Spoils = []*Spoil{}
 			for range player.Spoils {
 				redacted.Spoils = append(redacted.Spoils, redactedSpoil)
@@ -231,30 +539,56 @@ func (g GameState) RedactForPlayer(playerName string) *GameState {
 		}
 	}
 	g.Players = redactedPlayers
+	if fog != nil {
+		redactedTerrits := make(map[string]*TerritoryMut)
+		for name, territ := range g.Territs {
+			if fog.Sees(name) {
+				redactedTerrits[name] = territ
+			} else {
+				redactedTerrits[name] = &TerritoryMut{Owner: territ.Owner, Hidden: true}
+			}
+		}
+		g.Territs = redactedTerrits
+	}
 	return &g
 }
 
-func NewGameState(mapName string) GameState {
+func NewGameState(settings GameSettings, seed int64) GameState {
 	return GameState{
-		Phase:   Phase{Lobby: &LobbyPhase{}},
-		Territs: make(map[string]*TerritoryMut),
-		Map:     mapName,
+		Phase:           Phase{Lobby: &LobbyPhase{}},
+		Territs:         make(map[string]*TerritoryMut),
+		Map:             settings.Map,
+		Settings:        settings,
+		NextSpoilsBonus: settings.Spoils.NextBonus(0),
+		seed:            seed,
+		randomSource:    NewSeededRandom,
 	}
 }
 
-func (g *GameState) AddPlayer(player string) (*Event, error) {
+// SetRandomSource replaces the source of randomness, e.g. to script dice rolls.
+func (g *GameState) SetRandomSource(source RandomSource) {
+	g.randomSource = source
+}
+
+func (g *GameState) AddPlayer(player string, bot string) (*Event, error) {
 	if g.Phase.Lobby == nil {
 		return nil, fmt.Errorf("game is already started")
 	}
 
-	COLORS := []string{"red", "blue", "green", "yellow", "brown", "teal"}
+	if player == "" || player == NeutralOwner {
+		return nil, fmt.Errorf("invalid player name '%s'", player)
+	}
 	if g.findPlayer(player) != nil {
 		return nil, fmt.Errorf("player already joined")
 	}
+	if len(g.Players) >= g.Settings.MaxPlayers {
+		return nil, fmt.Errorf("game is full")
+	}
 	newPlayer := Player{
 		Name:   player,
-		Color:  COLORS[len(g.Players)],
+		Color:  playerColors[len(g.Players)],
 		Spoils: []*Spoil{},
+		Bot:    bot,
 	}
 	g.Players = append(g.Players, &newPlayer)
 	return &Event{
@@ -266,28 +600,61 @@ func (g *GameState) Start(m *Map) (*Event, error) {
 	if g.Phase.Lobby == nil {
 		return nil, fmt.Errorf("game is already started")
 	}
+	sides := make(map[string]bool)
+	for _, player := range g.Players {
+		sides[g.side(player)] = true
+	}
+	if g.Settings.Teams && len(sides) < 2 {
+		return nil, fmt.Errorf("game needs at least two teams")
+	}
+
+	for _, player := range g.Players {
+		player.TimeBank = time.Duration(g.Settings.TimeBankSeconds) * time.Second
+	}
 
 	SPOIL_COLORS := []string{"red", "blue", "green"}
-	for territ, _ := range m.Territs {
+	var territs []string
+	for territ := range m.Territs {
+		territs = append(territs, territ)
+	}
+	// Map iteration order is random; sort so that the pool only depends on the seed.
+	sort.Strings(territs)
+	for _, territ := range territs {
 		g.spoilPool = append(g.spoilPool, &Spoil{
 			Name:  territ,
 			Color: SPOIL_COLORS[len(g.spoilPool)%len(SPOIL_COLORS)],
 		})
 	}
 
-	g.initialDeploy(m)
-	g.calculateStats(m)
-	g.ActivePlayer = g.Players[rand.Int()%len(g.Players)].Name
-	g.Phase = Phase{Deploy: &DeployPhase{
-		Reinforcements: g.findPlayer(g.ActivePlayer).Reinforcements,
-	}}
+	if g.Settings.Draft.Drafted() {
+		g.startDraft(m)
+	} else {
+		g.initialDeploy(m)
+		g.startFirstTurn(m)
+	}
 	return &Event{
 		Snapshot: g,
 	}, nil
 }
 
+// startFirstTurn picks a random player to take the first turn.
+func (g *GameState) startFirstTurn(m *Map) {
+	g.Phase = Phase{Deploy: &DeployPhase{}}
+	g.calculateStats(m)
+	g.ActivePlayer = g.Players[g.rng.Intn(len(g.Players))].Name
+	if g.findPlayer(g.ActivePlayer).Eliminated {
+		// A player may have drafted no territory.
+		g.ActivePlayer = g.nextPlayerAfter(g.ActivePlayer, func(player *Player) bool {
+			return !player.Eliminated
+		}).Name
+	}
+	g.Turn = 1
+	g.startTurnClock()
+	g.Phase.Deploy.Reinforcements = g.findPlayer(g.ActivePlayer).Reinforcements
+}
+
 func (g *GameState) takeSpoil() *Spoil {
-	idx := rand.Intn(len(g.spoilPool))
+	idx := g.rng.Intn(len(g.spoilPool))
 	spoil := g.spoilPool[idx]
 	g.spoilPool[idx] = g.spoilPool[len(g.spoilPool)-1]
 	g.spoilPool = g.spoilPool[:len(g.spoilPool)-1]
@@ -298,6 +665,18 @@ func (g *GameState) replaceSpoil(spoil *Spoil) {
 	g.spoilPool = append(g.spoilPool, spoil)
 }
 
+// activeAdmin returns the player who may kick others: the game's creator, or
+// once they are out of the game, the first person still in it. Bots never
+// kick anyone, so they are passed over.
+func (g *GameState) activeAdmin() *Player {
+	for _, player := range g.Players {
+		if !player.Eliminated && player.Bot == "" {
+			return player
+		}
+	}
+	return nil
+}
+
 func (g *GameState) findPlayer(name string) *Player {
 	for idx := range g.Players {
 		if g.Players[idx].Name == name {
@@ -308,22 +687,57 @@ func (g *GameState) findPlayer(name string) *Player {
 }
 
 func (g *GameState) initialDeploy(m *Map) {
+	territs := g.deployNeutral(m)
+	var owners []string
+	for _, player := range g.Players {
+		owners = append(owners, player.Name)
+	}
+	if g.Settings.NeutralPlayer {
+		owners = append(owners, NeutralOwner)
+	}
+	for idx := range territs {
+		territ := &TerritoryMut{
+			Owner:  owners[idx%len(owners)],
+			Troops: g.Settings.StartingTroops,
+		}
+		if territ.Owner == NeutralOwner {
+			territ.Troops = g.neutralTroops()
+		}
+		g.Territs[territs[idx]] = territ
+	}
+}
+
+// deployNeutral places the territories the map holds for the neutral owner,
+// and returns the rest in random order.
+func (g *GameState) deployNeutral(m *Map) []string {
 	var territs []string
-	for territName := range m.Territs {
-		territs = append(territs, territName)
+	for territName, territ := range m.Territs {
+		if territ.Neutral > 0 {
+			// The map holds this territory for the neutral owner.
+			g.Territs[territName] = &TerritoryMut{
+				Owner:  NeutralOwner,
+				Troops: territ.Neutral,
+			}
+		} else {
+			territs = append(territs, territName)
+		}
 	}
-	rand.Shuffle(len(territs), func(i int, j int) {
+	sort.Strings(territs)
+	g.rng.Shuffle(len(territs), func(i int, j int) {
 		tmp := territs[i]
 		territs[i] = territs[j]
 		territs[j] = tmp
 	})
-	playerCount := len(g.Players)
-	for idx := range territs {
-		g.Territs[territs[idx]] = &TerritoryMut{
-			Owner:  g.Players[idx%playerCount].Name,
-			Troops: 3,
-		}
+	return territs
+}
+
+// neutralTroops returns the troops placed on each territory the neutral owner
+// starts with, unless the map says otherwise.
+func (g *GameState) neutralTroops() uint64 {
+	if g.Settings.NeutralTroops == 0 {
+		return g.Settings.StartingTroops
 	}
+	return g.Settings.NeutralTroops
 }
 
 func (g *GameState) calculateStats(m *Map) bool {
@@ -337,19 +751,25 @@ func (g *GameState) calculateStats(m *Map) bool {
 		counter.troops += territ.Troops
 		playerTerritCount[territ.Owner] = counter
 	}
-	eliminated := 0
+	// Territories held by the neutral owner count for nobody, and don't keep
+	// the game going.
+	sides := make(map[string]bool)
 	for idx := range g.Players {
 		player := g.Players[idx]
 		counter := playerTerritCount[g.Players[idx].Name]
 		player.Territories = counter.territs
 		player.Troops = counter.troops
-		player.Reinforcements = player.Territories / 3
-		if player.Reinforcements < 3 {
-			player.Reinforcements = 3
+		player.Reinforcements = player.Territories / g.Settings.ReinforcementDivisor
+		if player.Reinforcements < g.Settings.MinReinforcements {
+			player.Reinforcements = g.Settings.MinReinforcements
 		}
 		if player.Territories == 0 {
-			player.Eliminated = true
-			eliminated += 1
+			// Players may not have drafted a territory yet.
+			if !g.drafting() {
+				player.Eliminated = true
+			}
+		} else {
+			sides[g.side(player)] = true
 		}
 
 		// Calculate region bonuses
@@ -360,12 +780,70 @@ func (g *GameState) calculateStats(m *Map) bool {
 		}
 	}
 	// Game over condition
-	return eliminated == len(g.Players)-1
+	return len(sides) == 1
+}
+
+// side returns a key for the player's team, or for the player if they play
+// alone. The keys are prefixed, so a team named after a player is still a
+// different side.
+func (g *GameState) side(player *Player) string {
+	if player.Team != "" {
+		return "team:" + player.Team
+	}
+	return "player:" + player.Name
+}
+
+// allied returns true if both players are the same player or on the same
+// team.
+func (g *GameState) allied(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	playerA := g.findPlayer(a)
+	playerB := g.findPlayer(b)
+	return playerA != nil && playerB != nil && playerA.Team != "" && playerA.Team == playerB.Team
+}
+
+// gameOver returns the phase in which the player and their team have won.
+func (g *GameState) gameOver(winner string) Phase {
+	phase := &GameOverPhase{Winner: winner}
+	if team := g.findPlayer(winner).Team; team != "" {
+		phase.Team = team
+		for _, player := range g.Players {
+			if player.Team == team {
+				phase.Winners = append(phase.Winners, player.Name)
+			}
+		}
+	}
+	return Phase{GameOver: phase}
+}
+
+func (g *GameState) chooseTeam(team *TeamAction) ([]*Event, error) {
+	if !g.Settings.Teams {
+		return nil, fmt.Errorf("game is not played in teams")
+	}
+	target := team.Target
+	if target == "" {
+		target = team.Player
+	}
+	if target != team.Player && g.Players[0].Name != team.Player {
+		return nil, fmt.Errorf("only admin can choose the team of other players")
+	}
+	player := g.findPlayer(target)
+	if player == nil {
+		return nil, fmt.Errorf("player '%s' is not in the game", target)
+	}
+	player.Team = team.Team
+	return []*Event{{TeamChosen: &TeamAction{Player: team.Player, Target: target, Team: team.Team}}}, nil
 }
 
 func (g *GameState) playerOwnsRegion(player string, region *Region) bool {
+	if player == NeutralOwner {
+		// The neutral owner gets no reinforcements.
+		return false
+	}
 	for _, territ := range region.Territs {
-		if g.Territs[territ].Owner != player {
+		if t, found := g.Territs[territ]; !found || t.Owner != player {
 			return false
 		}
 	}
@@ -382,14 +860,38 @@ func (g *GameState) statsUpdate() *StatsChangedEvent {
 			Reinforcements: player.Reinforcements,
 			Eliminated:     player.Eliminated,
 			Spoils:         player.Spoils,
+			Pool:           player.Pool,
 		}
 	}
+	var teams map[string]*TeamStats
+	for _, player := range g.Players {
+		if player.Team == "" {
+			continue
+		}
+		if teams == nil {
+			teams = make(map[string]*TeamStats)
+		}
+		team, found := teams[player.Team]
+		if !found {
+			team = &TeamStats{Eliminated: true}
+			teams[player.Team] = team
+		}
+		team.Players = append(team.Players, player.Name)
+		team.Territories += player.Territories
+		team.Troops += player.Troops
+		team.Reinforcements += player.Reinforcements
+		team.Eliminated = team.Eliminated && player.Eliminated
+	}
 	return &StatsChangedEvent{
-		Updates: s,
+		Updates:         s,
+		Teams:           teams,
+		NextSpoilsBonus: g.NextSpoilsBonus,
 	}
 }
 
 func (g *GameState) selectNextPlayer() {
+	g.stopTurnClock()
+	defer g.startTurnClock()
 	for idx := range g.Players {
 		if g.Players[idx].Name == g.ActivePlayer {
 			nextIdx := idx
@@ -397,11 +899,12 @@ func (g *GameState) selectNextPlayer() {
 				nextIdx = (nextIdx + 1) % len(g.Players)
 				if !g.Players[nextIdx].Eliminated {
 					g.ActivePlayer = g.Players[nextIdx].Name
+					g.Turn += 1
 					// Check if we need to go to Spoils phase or
 					// deploy.
 					if g.Players[nextIdx].HasSpoils() {
 						g.Phase = Phase{
-							Spoils: &SpoilsPhase{Mandatory: len(g.Players[nextIdx].Spoils) >= 5},
+							Spoils: &SpoilsPhase{Mandatory: len(g.Players[nextIdx].Spoils) >= g.Settings.HandLimit},
 						}
 					} else {
 						g.Phase = Phase{
@@ -417,14 +920,54 @@ func (g *GameState) selectNextPlayer() {
 	}
 }
 
-func (g *GameState) ApplyAction(m *Map, action *Action) ([]*Event, error) {
-	if g.Phase.Spoils != nil {
+// ApplyAction applies the action at the given time. Replays must pass the time
+// the action was originally applied, since turn deadlines depend on it.
+func (g *GameState) ApplyAction(m *Map, action *Action, now time.Time) ([]*Event, error) {
+	// Derive the RNG from the seed and the number of actions applied so far, so
+	// that replaying the same actions always produces the same game.
+	g.rng = g.randomSource(g.seed + int64(g.actions))
+	g.now = now
+	events, err := g.applyAction(m, action)
+	if err != nil {
+		return nil, err
+	}
+	g.actions += 1
+	for _, event := range events {
+		g.Seq += 1
+		event.Seq = g.Seq
+	}
+	return events, nil
+}
+
+func (g *GameState) applyAction(m *Map, action *Action) ([]*Event, error) {
+	if action.Timeout != nil {
+		return g.applyTimeoutAction(m, action.Timeout)
+	} else if action.Resign != nil {
+		return g.removePlayer(m, action.Resign.Player, action.Resign.Heir, false)
+	} else if action.Kick != nil {
+		if admin := g.activeAdmin(); admin == nil || admin.Name != action.Kick.Player {
+			return nil, fmt.Errorf("only admin can kick players")
+		}
+		if action.Kick.Target == action.Kick.Player {
+			return nil, fmt.Errorf("admin cannot kick themselves")
+		}
+		return g.removePlayer(m, action.Kick.Target, action.Kick.Heir, true)
+	} else if g.Phase.Draft != nil {
+		if action.Bid != nil {
+			return g.applyBidAction(m, action.Bid)
+		}
+		return g.applyClaimAction(m, action.Claim)
+	} else if g.Phase.Placement != nil {
+		return g.applyPlaceAction(m, action.Place)
+	} else if g.Phase.Spoils != nil {
 		return g.applySpoilsAction(action.Spoils)
 	} else if g.Phase.Deploy != nil {
 		return g.applyDeployAction(action.Deploy)
 	} else if g.Phase.Attack != nil {
 		if action.EndAttack != nil {
 			return g.applyEndAttackAction(action.EndAttack)
+		} else if action.Blitz != nil {
+			return g.applyBlitzAction(m, action.Blitz)
 		}
 		return g.applyAttackAction(m, action.Attack)
 	} else if g.Phase.Advance != nil {
@@ -436,11 +979,13 @@ func (g *GameState) ApplyAction(m *Map, action *Action) ([]*Event, error) {
 		return g.applyReinforceAction(m, action.Reinforce)
 	} else if g.Phase.Lobby != nil {
 		if action.JoinGame != nil {
-			event, err := g.AddPlayer(action.JoinGame.Player)
+			event, err := g.AddPlayer(action.JoinGame.Player, action.JoinGame.Bot)
 			if err != nil {
 				return nil, err
 			}
 			return []*Event{event}, nil
+		} else if action.ChooseTeam != nil {
+			return g.chooseTeam(action.ChooseTeam)
 		} else if action.StartGame != nil {
 			if g.Players[0].Name != action.StartGame.Player {
 				return nil, fmt.Errorf("only admin can start the game")
@@ -453,8 +998,10 @@ func (g *GameState) ApplyAction(m *Map, action *Action) ([]*Event, error) {
 		} else {
 			return nil, fmt.Errorf("game has not started")
 		}
+	} else if g.Phase.GameOver != nil {
+		return nil, fmt.Errorf("game is over")
 	} else {
-		panic("invalid game phase")
+		return nil, fmt.Errorf("invalid game phase '%s'", g.Phase.Name())
 	}
 }
 
@@ -473,10 +1020,11 @@ func (g *GameState) applySpoilsAction(spoils *SpoilsAction) ([]*Event, error) {
 		return []*Event{
 			{
 				PhaseChanged: &PhaseChangedEvent{
-					OldPlayer: g.ActivePlayer,
-					NewPlayer: g.ActivePlayer,
-					OldPhase:  oldPhase,
-					NewPhase:  g.Phase,
+					OldPlayer:    g.ActivePlayer,
+					NewPlayer:    g.ActivePlayer,
+					OldPhase:     oldPhase,
+					NewPhase:     g.Phase,
+					TurnDeadline: g.TurnDeadline,
 				},
 			},
 		}, nil
@@ -514,32 +1062,26 @@ func (g *GameState) applySpoilsAction(spoils *SpoilsAction) ([]*Event, error) {
 		}
 	}
 
-	var bonus uint64
-	if red == 3 {
-		bonus = 4
-	} else if green == 3 {
-		bonus = 6
-	} else if blue == 3 {
-		bonus = 8
-	} else if red == 1 && green == 1 && blue == 1 {
-		bonus = 10
-	} else {
-		return nil, fmt.Errorf("invalid spoils")
+	bonus, err := g.Settings.Spoils.Bonus(red, green, blue, g.SetsCashed)
+	if err != nil {
+		return nil, err
 	}
+	g.SetsCashed += 1
+	g.NextSpoilsBonus = g.Settings.Spoils.NextBonus(g.SetsCashed)
 
 	deployments := make(map[string]uint64)
 	player.Spoils = keep
 	for _, spoil := range cash {
 		territ := g.Territs[spoil.Name]
-		if territ.Owner == spoils.Player {
-			territ.Troops += 2
-			deployments[spoil.Name] = 2
+		if territ.Owner == spoils.Player && g.Settings.Spoils.Territory > 0 {
+			territ.Troops += g.Settings.Spoils.Territory
+			deployments[spoil.Name] = g.Settings.Spoils.Territory
 		}
 		g.replaceSpoil(spoil)
 	}
 
 	oldPhase := g.Phase
-	if len(player.Spoils) >= 5 {
+	if len(player.Spoils) >= g.Settings.HandLimit {
 		// More spoils need to be played. Accumulate the previous bonus with this one.
 		g.Phase = Phase{Spoils: &SpoilsPhase{Mandatory: true, Conquered: oldPhase.Spoils.Conquered, BonusSoFar: oldPhase.Spoils.BonusSoFar + bonus}}
 	} else {
@@ -554,10 +1096,11 @@ func (g *GameState) applySpoilsAction(spoils *SpoilsAction) ([]*Event, error) {
 	events := []*Event{
 		{
 			PhaseChanged: &PhaseChangedEvent{
-				OldPlayer: g.ActivePlayer,
-				NewPlayer: g.ActivePlayer,
-				OldPhase:  oldPhase,
-				NewPhase:  g.Phase,
+				OldPlayer:    g.ActivePlayer,
+				NewPlayer:    g.ActivePlayer,
+				OldPhase:     oldPhase,
+				NewPhase:     g.Phase,
+				TurnDeadline: g.TurnDeadline,
 			},
 		},
 		{
@@ -583,7 +1126,10 @@ func (g *GameState) applyDeployAction(deploy *DeployAction) ([]*Event, error) {
 	if g.ActivePlayer != deploy.Player {
 		return nil, fmt.Errorf("it is not your turn")
 	}
-	player := g.findPlayer(deploy.Player)
+
+	// Validate the whole deployment before touching any territory.
+	remaining := g.Phase.Deploy.Reinforcements
+	var total uint64
 	for territ, troops := range deploy.Deployments {
 		territMut, found := g.Territs[territ]
 		if !found {
@@ -592,21 +1138,39 @@ func (g *GameState) applyDeployAction(deploy *DeployAction) ([]*Event, error) {
 		if territMut.Owner != deploy.Player {
 			return nil, fmt.Errorf("territory '%s' does not belong to you", territ)
 		}
-		territMut.Troops += troops
+		// Check each value on its own so that the total can't wrap around.
+		if troops > remaining-total {
+			return nil, fmt.Errorf("cannot deploy more than %d troops", remaining)
+		}
+		total += troops
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("must deploy at least 1 troop")
+	}
+
+	player := g.findPlayer(deploy.Player)
+	for territ, troops := range deploy.Deployments {
+		g.Territs[territ].Troops += troops
 
 		// Update the player's total troop count.
 		player.Troops += troops
 	}
 	oldPhase := g.Phase
-	g.Phase = Phase{Attack: &AttackPhase{Conquered: oldPhase.Deploy.Conquered}}
+	if total < remaining {
+		// A partial deployment. Stay in the deploy phase until every troop is placed.
+		g.Phase = Phase{Deploy: &DeployPhase{Reinforcements: remaining - total, Conquered: oldPhase.Deploy.Conquered}}
+	} else {
+		g.Phase = Phase{Attack: &AttackPhase{Conquered: oldPhase.Deploy.Conquered}}
+	}
 	return []*Event{
 		{Deploy: deploy},
 		{StatsChanged: g.statsUpdate()},
 		{PhaseChanged: &PhaseChangedEvent{
-			OldPlayer: deploy.Player,
-			NewPlayer: deploy.Player,
-			OldPhase:  oldPhase,
-			NewPhase:  g.Phase,
+			OldPlayer:    deploy.Player,
+			NewPlayer:    deploy.Player,
+			OldPhase:     oldPhase,
+			NewPhase:     g.Phase,
+			TurnDeadline: g.TurnDeadline,
 		}},
 	}, nil
 }
@@ -622,10 +1186,10 @@ type DiceRoll struct {
 	dice []int
 }
 
-func RollDice(num uint64) DiceRoll {
+func RollDice(rng Random, num uint64) DiceRoll {
 	dice := make([]int, num)
 	for die := range dice {
-		dice[die] = (rand.Int() % 6) + 1
+		dice[die] = rng.Intn(6) + 1
 	}
 	sort.Sort(sort.Reverse(sort.IntSlice(dice)))
 	return DiceRoll{dice}
@@ -648,59 +1212,116 @@ func (attacker *DiceRoll) ResolveAgainstDefender(defender *DiceRoll) (uint64, ui
 	return attacker_loss, defender_loss
 }
 
-func (g *GameState) applyAttackAction(m *Map, attack *AttackAction) ([]*Event, error) {
-	if attack == nil {
-		return nil, fmt.Errorf("action does not apply to 'attack' phase")
-	}
+// checkAttack returns the territories involved in the attack, or an error if
+// the attack isn't allowed.
+func (g *GameState) checkAttack(m *Map, attack *AttackAction) (*TerritoryMut, *TerritoryMut, error) {
 	if g.ActivePlayer != attack.Player {
-		return nil, fmt.Errorf("it is not your turn")
+		return nil, nil, fmt.Errorf("it is not your turn")
 	}
 	from, found := g.Territs[attack.From]
 	if !found {
-		return nil, fmt.Errorf("territory '%s' does not exist", attack.From)
+		return nil, nil, fmt.Errorf("territory '%s' does not exist", attack.From)
 	}
 	if from.Owner != attack.Player {
-		return nil, fmt.Errorf("territory '%s' does not belong to you", attack.From)
+		return nil, nil, fmt.Errorf("territory '%s' does not belong to you", attack.From)
 	}
 	to, found := g.Territs[attack.To]
 	if !found {
-		return nil, fmt.Errorf("territory '%s' does not exist", attack.To)
+		return nil, nil, fmt.Errorf("territory '%s' does not exist", attack.To)
 	}
 	if to.Owner == attack.Player {
-		return nil, fmt.Errorf("target territory of attack '%s' belongs to you", attack.To)
+		return nil, nil, fmt.Errorf("target territory of attack '%s' belongs to you", attack.To)
+	}
+	if g.Settings.NoFriendlyFire && g.allied(attack.Player, to.Owner) {
+		return nil, nil, fmt.Errorf("target territory of attack '%s' belongs to your team", attack.To)
 	}
 	if from.Troops <= 1 {
-		return nil, fmt.Errorf("not enough troops in territory '%s' to attack", attack.From)
+		return nil, nil, fmt.Errorf("not enough troops in territory '%s' to attack", attack.From)
 	}
 	if !m.IsAdjacent(attack.From, attack.To) {
-		return nil, fmt.Errorf("territory '%s' is not attackable from '%s'", attack.To, attack.From)
+		return nil, nil, fmt.Errorf("territory '%s' is not attackable from '%s'", attack.To, attack.From)
 	}
-	attackerDieRolls := RollDice(min(from.Troops-1, 3))
-	defenderDieRolls := RollDice(min(to.Troops, 2))
+	return from, to, nil
+}
+
+// rollAttack rolls the dice for a single attack and removes the losses. The
+// attacker keeps back reserve troops that are never rolled.
+func (g *GameState) rollAttack(from *TerritoryMut, to *TerritoryMut, reserve uint64) *AttackRoll {
+	attackerDieRolls := RollDice(g.rng, min(from.Troops-reserve, 3))
+	defenderDieRolls := RollDice(g.rng, min(to.Troops, 2))
 	attacker_loss, defender_loss := attackerDieRolls.ResolveAgainstDefender(&defenderDieRolls)
 	from.Troops -= attacker_loss
 	to.Troops -= defender_loss
 
 	// Update the players' total troop counts.
-	fromPlayer := g.findPlayer(from.Owner)
-	toPlayer := g.findPlayer(to.Owner)
-	fromPlayer.Troops -= attacker_loss
-	toPlayer.Troops -= defender_loss
+	g.findPlayer(from.Owner).Troops -= attacker_loss
+	// Neutral territories have no player.
+	if toPlayer := g.findPlayer(to.Owner); toPlayer != nil {
+		toPlayer.Troops -= defender_loss
+	}
+	return &AttackRoll{
+		AttackerDice:   attackerDieRolls.dice,
+		DefenderDice:   defenderDieRolls.dice,
+		AttackerLosses: attacker_loss,
+		DefenderLosses: defender_loss,
+	}
+}
 
-	events := []*Event{
-		{
-			Attack: &AttackEvent{
-				AttackAction:   *attack,
-				Defender:       to.Owner,
-				AttackerDice:   attackerDieRolls.dice,
-				DefenderDice:   defenderDieRolls.dice,
-				AttackerLosses: attacker_loss,
-				DefenderLosses: defender_loss,
-				Conquered:      to.Troops == 0,
-			},
-		},
+func (g *GameState) applyAttackAction(m *Map, attack *AttackAction) ([]*Event, error) {
+	if attack == nil {
+		return nil, fmt.Errorf("action does not apply to 'attack' phase")
+	}
+	from, to, err := g.checkAttack(m, attack)
+	if err != nil {
+		return nil, err
+	}
+	event := &AttackEvent{
+		AttackAction: *attack,
+		Defender:     to.Owner,
+		AttackRoll:   *g.rollAttack(from, to, 1),
+		Conquered:    to.Troops == 0,
 	}
-	if to.Troops == 0 {
+	return g.resolveAttack(m, event, from, to), nil
+}
+
+func (g *GameState) applyBlitzAction(m *Map, blitz *BlitzAction) ([]*Event, error) {
+	from, to, err := g.checkAttack(m, &blitz.AttackAction)
+	if err != nil {
+		return nil, err
+	}
+	minTroops := blitz.MinTroops
+	if minTroops < 1 {
+		minTroops = 1
+	}
+	if from.Troops <= minTroops {
+		return nil, fmt.Errorf("territory '%s' already has no more than %d troops", blitz.From, minTroops)
+	}
+	event := &AttackEvent{
+		AttackAction: blitz.AttackAction,
+		Defender:     to.Owner,
+		Blitz:        true,
+		AttackRoll:   AttackRoll{AttackerDice: []int{}, DefenderDice: []int{}},
+	}
+	for to.Troops > 0 && from.Troops > minTroops && (blitz.MaxRolls == 0 || event.RollCount < blitz.MaxRolls) {
+		roll := g.rollAttack(from, to, minTroops)
+		event.RollCount += 1
+		event.AttackerLosses += roll.AttackerLosses
+		event.DefenderLosses += roll.DefenderLosses
+		if blitz.Detail {
+			event.Rolls = append(event.Rolls, roll)
+		}
+	}
+	event.Conquered = to.Troops == 0
+	return g.resolveAttack(m, event, from, to), nil
+}
+
+// resolveAttack finishes an attack once the dice are rolled, handing over the
+// territory if it was conquered.
+func (g *GameState) resolveAttack(m *Map, event *AttackEvent, from *TerritoryMut, to *TerritoryMut) []*Event {
+	events := []*Event{{Attack: event}}
+	if event.Conquered {
+		toPlayer := g.findPlayer(to.Owner)
+
 		// Change ownership
 		to.Owner = from.Owner
 		from.Troops -= 1
@@ -711,25 +1332,33 @@ func (g *GameState) applyAttackAction(m *Map, attack *AttackAction) ([]*Event, e
 		// Adjust all stats.
 		if g.calculateStats(m) {
 			// The game is over!
-			g.Phase = Phase{GameOver: &GameOverPhase{Winner: attack.Player}}
+			g.Phase = g.gameOver(event.Player)
+			g.TurnDeadline = nil
 		} else {
 			// Move to the advance phase.
-			g.Phase = Phase{Advance: &AdvancePhase{From: attack.From, To: attack.To}}
+			g.Phase = Phase{Advance: &AdvancePhase{From: event.From, To: event.To}}
 		}
-		if toPlayer.Eliminated {
+		if toPlayer != nil && toPlayer.Eliminated {
 			// Take the spoils!
+			fromPlayer := g.findPlayer(from.Owner)
 			fromPlayer.Spoils = append(fromPlayer.Spoils, toPlayer.Spoils...)
 			toPlayer.Spoils = []*Spoil{}
 		}
+
 		events = append(events, &Event{PhaseChanged: &PhaseChangedEvent{
-			OldPlayer: attack.Player,
-			NewPlayer: attack.Player,
-			OldPhase:  oldPhase,
-			NewPhase:  g.Phase,
+			OldPlayer:    event.Player,
+			NewPlayer:    event.Player,
+			OldPhase:     oldPhase,
+			NewPhase:     g.Phase,
+			TurnDeadline: g.TurnDeadline,
 		}})
 	}
 	events = append(events, &Event{StatsChanged: g.statsUpdate()})
-	return events, nil
+	if event.Conquered && g.Settings.FogOfWar {
+		// The conquest changed what both players can see.
+		events = append(events, &Event{Snapshot: g})
+	}
+	return events
 }
 
 func (g *GameState) applyEndAttackAction(endAttack *EndPhaseAction) ([]*Event, error) {
@@ -744,10 +1373,11 @@ func (g *GameState) applyEndAttackAction(endAttack *EndPhaseAction) ([]*Event, e
 	return []*Event{
 		{
 			PhaseChanged: &PhaseChangedEvent{
-				OldPlayer: endAttack.Player,
-				NewPlayer: endAttack.Player,
-				OldPhase:  oldPhase,
-				NewPhase:  g.Phase,
+				OldPlayer:    endAttack.Player,
+				NewPlayer:    endAttack.Player,
+				OldPhase:     oldPhase,
+				NewPhase:     g.Phase,
+				TurnDeadline: g.TurnDeadline,
 			},
 		},
 	}, nil
@@ -780,7 +1410,7 @@ func (g *GameState) applyAdvanceAction(advance *MoveAction) ([]*Event, error) {
 	to.Troops += advance.Troops
 	from.Troops -= advance.Troops
 	oldPhase := g.Phase
-	if len(g.findPlayer(g.ActivePlayer).Spoils) >= 5 {
+	if len(g.findPlayer(g.ActivePlayer).Spoils) >= g.Settings.HandLimit {
 		g.Phase = Phase{Spoils: &SpoilsPhase{Mandatory: true, Conquered: true}}
 	} else {
 		g.Phase = Phase{Attack: &AttackPhase{Conquered: true}}
@@ -788,10 +1418,11 @@ func (g *GameState) applyAdvanceAction(advance *MoveAction) ([]*Event, error) {
 	return []*Event{
 		{Advance: advance},
 		{PhaseChanged: &PhaseChangedEvent{
-			OldPlayer: advance.Player,
-			NewPlayer: advance.Player,
-			OldPhase:  oldPhase,
-			NewPhase:  g.Phase,
+			OldPlayer:    advance.Player,
+			NewPlayer:    advance.Player,
+			OldPhase:     oldPhase,
+			NewPhase:     g.Phase,
+			TurnDeadline: g.TurnDeadline,
 		}},
 	}, nil
 }
@@ -837,10 +1468,11 @@ func (g *GameState) applyReinforceAction(m *Map, reinforce *MoveAction) ([]*Even
 	g.selectNextPlayer()
 	events = append(events, &Event{Reinforce: reinforce},
 		&Event{PhaseChanged: &PhaseChangedEvent{
-			OldPlayer: reinforce.Player,
-			NewPlayer: g.ActivePlayer,
-			OldPhase:  oldPhase,
-			NewPhase:  g.Phase,
+			OldPlayer:    reinforce.Player,
+			NewPlayer:    g.ActivePlayer,
+			OldPhase:     oldPhase,
+			NewPhase:     g.Phase,
+			TurnDeadline: g.TurnDeadline,
 		},
 		})
 	return events, nil
@@ -863,18 +1495,286 @@ func (g *GameState) applyEndReinforceAction(endReinforce *EndPhaseAction) ([]*Ev
 	g.selectNextPlayer()
 	events = append(events,
 		&Event{PhaseChanged: &PhaseChangedEvent{
-			OldPlayer: endReinforce.Player,
-			NewPlayer: g.ActivePlayer,
-			OldPhase:  oldPhase,
-			NewPhase:  g.Phase,
+			OldPlayer:    endReinforce.Player,
+			NewPlayer:    g.ActivePlayer,
+			OldPhase:     oldPhase,
+			NewPhase:     g.Phase,
+			TurnDeadline: g.TurnDeadline,
 		},
 		})
 	return events, nil
 }
 
+// removePlayer takes a player out of a started game. Their territories go to
+// the heir, or become neutral if there is none. The heir also takes their
+// spoils, which otherwise go back into the pool. If the player was active, the
+// next player takes over.
+func (g *GameState) removePlayer(m *Map, name string, heirName string, kicked bool) ([]*Event, error) {
+	if g.Phase.Lobby != nil {
+		return nil, fmt.Errorf("game has not started")
+	}
+	if g.Phase.GameOver != nil {
+		return nil, fmt.Errorf("game is over")
+	}
+	player := g.findPlayer(name)
+	if player == nil {
+		return nil, fmt.Errorf("player '%s' is not in the game", name)
+	}
+	if player.Eliminated {
+		return nil, fmt.Errorf("player '%s' is already out of the game", name)
+	}
+	var heir *Player
+	if heirName != "" {
+		heir = g.findPlayer(heirName)
+		if heir == nil || heir.Eliminated || heir == player {
+			return nil, fmt.Errorf("player '%s' cannot be the heir", heirName)
+		}
+	}
+
+	owner := NeutralOwner
+	if heir != nil {
+		owner = heir.Name
+		heir.Spoils = append(heir.Spoils, player.Spoils...)
+	} else {
+		for _, spoil := range player.Spoils {
+			g.replaceSpoil(spoil)
+		}
+	}
+	player.Spoils = []*Spoil{}
+	player.Resigned = true
+	for _, territ := range g.Territs {
+		if territ.Owner == name {
+			territ.Owner = owner
+		}
+	}
+
+	gameOver := g.calculateStats(m)
+	if g.drafting() {
+		// Players who haven't drafted a territory yet are still in the game,
+		// so the player is taken out explicitly, and their pool goes with
+		// their territories.
+		player.Eliminated = true
+		if heir != nil {
+			heir.Pool += player.Pool
+		}
+		player.Pool = 0
+		sides := make(map[string]bool)
+		for _, remaining := range g.Players {
+			if !remaining.Eliminated {
+				sides[g.side(remaining)] = true
+			}
+		}
+		gameOver = len(sides) == 1
+	}
+	if gameOver {
+		for _, remaining := range g.Players {
+			if !remaining.Eliminated {
+				g.Phase = g.gameOver(remaining.Name)
+			}
+		}
+		g.TurnDeadline = nil
+	} else if g.drafting() {
+		g.leaveDraft(m, name)
+	} else if g.ActivePlayer == name {
+		g.selectNextPlayer()
+	}
+	// Territories changed hands all over the map, so send everything.
+	return []*Event{
+		{Resigned: &ResignedEvent{Player: name, Heir: heirName, Kicked: kicked}},
+		{Snapshot: g},
+	}, nil
+}
+
+// startTurnClock sets the deadline of the active player's turn. Abandoned
+// players get no time at all, unless everyone has abandoned the game, in which
+// case there is no deadline until somebody comes back.
+func (g *GameState) startTurnClock() {
+	if g.Settings.TurnSeconds == 0 {
+		return
+	}
+	player := g.findPlayer(g.ActivePlayer)
+	if player.Abandoned && g.allAbandoned() {
+		return
+	}
+	deadline := g.now
+	if !player.Abandoned {
+		deadline = deadline.Add(time.Duration(g.Settings.TurnSeconds)*time.Second + player.TimeBank)
+	}
+	g.TurnDeadline = &deadline
+}
+
+// stopTurnClock ends the active player's turn, taking the time they went over
+// the turn's limit from their time bank. Players who finish a turn themselves
+// are no longer abandoned.
+func (g *GameState) stopTurnClock() {
+	if g.TurnDeadline == nil {
+		return
+	}
+	player := g.findPlayer(g.ActivePlayer)
+	if g.timingOut {
+		player.TimeBank = 0
+		player.Timeouts += 1
+		if g.Settings.AbandonAfter > 0 && player.Timeouts >= g.Settings.AbandonAfter {
+			player.Abandoned = true
+		}
+	} else {
+		remaining := g.TurnDeadline.Sub(g.now)
+		if remaining < 0 {
+			remaining = 0
+		}
+		if remaining < player.TimeBank {
+			player.TimeBank = remaining
+		}
+		player.Timeouts = 0
+		player.Abandoned = false
+	}
+	g.TurnDeadline = nil
+}
+
+func (g *GameState) allAbandoned() bool {
+	for _, player := range g.Players {
+		if !player.Eliminated && !player.Abandoned {
+			return false
+		}
+	}
+	return true
+}
+
+// autoPlayAction returns the action played for a player whose turn timed out.
+// It places reinforcements evenly along the player's borders and otherwise
+// ends the turn as quickly as possible.
+func (g *GameState) autoPlayAction(m *Map) *Action {
+	player := g.ActivePlayer
+	switch {
+	case g.drafting():
+		return g.autoDraftAction(m)
+	case g.Phase.Spoils != nil:
+		var set []string
+		if g.Phase.Spoils.Mandatory {
+			set = spoilsSet(g.findPlayer(player).Spoils)
+		}
+		return &Action{Spoils: &SpoilsAction{Player: player, Spoils: set}}
+	case g.Phase.Deploy != nil:
+		return &Action{Deploy: &DeployAction{
+			Player:      player,
+			Deployments: g.borderDeployments(m, player, g.Phase.Deploy.Reinforcements),
+		}}
+	case g.Phase.Attack != nil:
+		return &Action{EndAttack: &EndPhaseAction{Player: player}}
+	case g.Phase.Advance != nil:
+		return &Action{Advance: &MoveAction{Player: player, From: g.Phase.Advance.From, To: g.Phase.Advance.To}}
+	}
+	return &Action{EndReinforce: &EndPhaseAction{Player: player}}
+}
+
+func (g *GameState) applyTimeoutAction(m *Map, timeout *TimeoutAction) ([]*Event, error) {
+	if g.ActivePlayer != timeout.Player {
+		return nil, fmt.Errorf("it is not the turn of player '%s'", timeout.Player)
+	}
+	if g.TurnDeadline == nil {
+		return nil, fmt.Errorf("turn has no time limit")
+	}
+	if g.now.Before(*g.TurnDeadline) {
+		return nil, fmt.Errorf("turn has not timed out")
+	}
+	timeoutEvent := &TimeoutEvent{Player: timeout.Player}
+	events := []*Event{{Timeout: timeoutEvent}}
+	// The turn ends once the player passes, which is when stopTurnClock counts
+	// the timeout and starts a new deadline.
+	g.timingOut = true
+	defer func() { g.timingOut = false }()
+	played, err := g.autoPlayTurn(m)
+	if err != nil {
+		// Nothing was played, so the turn can still be passed on cleanly.
+		timeoutEvent.Passed = true
+		played = g.passTurn(m)
+	}
+	events = append(events, played...)
+	timeoutEvent.Abandoned = g.findPlayer(timeout.Player).Abandoned
+	return events, nil
+}
+
+// autoPlayTurn plays the rest of the active player's turn. The actions are
+// applied to a copy of the state, which replaces the state only once the
+// whole turn is played, so a failed action leaves nothing half done.
+func (g *GameState) autoPlayTurn(m *Map) ([]*Event, error) {
+	auto, err := g.clone()
+	if err != nil {
+		return nil, err
+	}
+	var events []*Event
+	deadline := auto.TurnDeadline
+	for auto.TurnDeadline == deadline {
+		played, err := auto.applyAction(m, auto.autoPlayAction(m))
+		if err != nil {
+			return nil, fmt.Errorf("automatic action failed: %v", err)
+		}
+		events = append(events, played...)
+	}
+	*g = *auto
+	// Snapshots must show the state that goes on.
+	for _, event := range events {
+		if event.Snapshot != nil {
+			event.Snapshot = g
+		}
+	}
+	return events, nil
+}
+
+// passTurn ends the active player's turn without playing any of it.
+func (g *GameState) passTurn(m *Map) []*Event {
+	oldPhase := g.Phase
+	oldPlayer := g.ActivePlayer
+	switch {
+	case g.Phase.Placement != nil:
+		g.nextPlacementTurn(m, oldPlayer)
+	case g.Phase.Draft != nil:
+		// Any auction in progress is called off.
+		return g.nextDraftTurn(m, oldPlayer)
+	default:
+		g.selectNextPlayer()
+	}
+	return []*Event{{PhaseChanged: &PhaseChangedEvent{
+		OldPlayer:    oldPlayer,
+		NewPlayer:    g.ActivePlayer,
+		OldPhase:     oldPhase,
+		NewPhase:     g.Phase,
+		TurnDeadline: g.TurnDeadline,
+	}}}
+}
+
+// borderDeployments spreads the troops evenly along the player's borders.
+func (g *GameState) borderDeployments(m *Map, player string, troops uint64) map[string]uint64 {
+	territs := ownedTerrits(g, player)
+	var borders []string
+	for _, territ := range territs {
+		if len(enemyNeighbours(g, m, territ, player)) > 0 {
+			borders = append(borders, territ)
+		}
+	}
+	if len(borders) == 0 {
+		borders = territs
+	}
+	deployments := make(map[string]uint64)
+	for i := uint64(0); i < troops; i++ {
+		deployments[borders[i%uint64(len(borders))]] += 1
+	}
+	return deployments
+}
+
 func (g *GameState) Owns(owner string, territ string) bool {
 	if t, found := g.Territs[territ]; found {
 		return t.Owner == owner
 	}
 	return false
 }
+
+func (g *GameState) Passes(owner string, territ string) bool {
+	if t, found := g.Territs[territ]; found {
+		if g.Settings.AlliedReinforce {
+			return t.Owner != NeutralOwner && g.allied(owner, t.Owner)
+		}
+		return t.Owner == owner
+	}
+	return false
+}
@@ -0,0 +1,803 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+// testTime is when test games are played.
+var testTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ScriptedRandom returns predetermined values so that tests can roll exact
+// dice. It panics if it runs out of values.
+type ScriptedRandom struct {
+	Values []int
+}
+
+// NewScriptedDice returns a ScriptedRandom that rolls the given dice in order.
+func NewScriptedDice(dice ...int) *ScriptedRandom {
+	r := &ScriptedRandom{}
+	for _, die := range dice {
+		r.Values = append(r.Values, die-1)
+	}
+	return r
+}
+
+func (r *ScriptedRandom) Intn(n int) int {
+	value := r.Values[0] % n
+	r.Values = r.Values[1:]
+	return value
+}
+
+// Shuffle leaves the order unchanged.
+func (r *ScriptedRandom) Shuffle(n int, swap func(i int, j int)) {}
+
+// Source returns a RandomSource that always uses this ScriptedRandom,
+// regardless of the seed.
+func (r *ScriptedRandom) Source() RandomSource {
+	return func(seed int64) Random {
+		return r
+	}
+}
+
+func loadTestMap(t *testing.T) *Map {
+	t.Helper()
+	m, err := LoadMap("../maps/hk.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// newTestState starts a game between the players with the given settings.
+func newTestState(t *testing.T, m *Map, settings GameSettings, players ...string) *GameState {
+	t.Helper()
+	state := NewGameState(settings, 1)
+	actions := []*Action{}
+	for _, player := range players {
+		actions = append(actions, &Action{JoinGame: &JoinGameAction{Player: player}})
+	}
+	actions = append(actions, &Action{StartGame: &StartGameAction{Player: players[0]}})
+	for _, action := range actions {
+		mustApply(t, &state, m, action)
+	}
+	return &state
+}
+
+func mustApply(t *testing.T, state *GameState, m *Map, action *Action) []*Event {
+	t.Helper()
+	events, err := state.ApplyAction(m, action, testTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+// attackFrom deploys all reinforcements and moves to the attack phase,
+// returning a territory of the active player and an enemy neighbour.
+func attackFrom(t *testing.T, state *GameState, m *Map) (string, string) {
+	t.Helper()
+	player := state.ActivePlayer
+	for _, from := range ownedTerrits(state, player) {
+		if enemies := enemyNeighbours(state, m, from, player); len(enemies) > 0 {
+			mustApply(t, state, m, &Action{Deploy: &DeployAction{
+				Player:      player,
+				Deployments: map[string]uint64{from: state.Phase.Deploy.Reinforcements},
+			}})
+			return from, enemies[0]
+		}
+	}
+	t.Fatal("no territory to attack from")
+	return "", ""
+}
+
+func TestActAs(t *testing.T) {
+	action := &Action{Deploy: &DeployAction{}}
+	if err := action.ActAs("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if action.Deploy.Player != "alice" {
+		t.Errorf("expected the player to be filled in, got '%s'", action.Deploy.Player)
+	}
+	if err := action.ActAs("bob"); err == nil {
+		t.Error("bob acted as alice")
+	}
+
+	// Every sub-action must be the user's own.
+	action = &Action{
+		EndAttack:    &EndPhaseAction{Player: "alice"},
+		EndReinforce: &EndPhaseAction{Player: "bob"},
+	}
+	if err := action.ActAs("alice"); err == nil {
+		t.Error("alice acted as bob")
+	}
+}
+
+func TestDeployMustAddUpToReinforcements(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	player := state.ActivePlayer
+	territ := ownedTerrits(state, player)[0]
+	reinforcements := state.Phase.Deploy.Reinforcements
+	deploy := func(troops uint64) *Action {
+		return &Action{Deploy: &DeployAction{Player: player, Deployments: map[string]uint64{territ: troops}}}
+	}
+
+	for _, troops := range []uint64{0, reinforcements + 1} {
+		if _, err := state.ApplyAction(m, deploy(troops), testTime); err == nil {
+			t.Errorf("deployed %d of %d troops", troops, reinforcements)
+		}
+	}
+	mustApply(t, state, m, deploy(1))
+	if state.Phase.Deploy == nil || state.Phase.Deploy.Reinforcements != reinforcements-1 {
+		t.Fatalf("expected %d troops left to deploy, got %+v", reinforcements-1, state.Phase)
+	}
+	mustApply(t, state, m, deploy(reinforcements-1))
+	if state.Phase.Attack == nil {
+		t.Errorf("expected the attack phase, got %+v", state.Phase)
+	}
+}
+
+func TestDeployRejectsWrappingTotal(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	player := state.ActivePlayer
+	territs := ownedTerrits(state, player)
+	reinforcements := state.Phase.Deploy.Reinforcements
+	troops := state.Territs[territs[0]].Troops
+
+	_, err := state.ApplyAction(m, &Action{Deploy: &DeployAction{
+		Player: player,
+		Deployments: map[string]uint64{
+			territs[0]: math.MaxUint64 - 4,
+			territs[1]: reinforcements + 5,
+		},
+	}}, testTime)
+	if err == nil {
+		t.Fatal("deployment that wraps around was accepted")
+	}
+	if state.Territs[territs[0]].Troops != troops {
+		t.Errorf("troops changed to %d", state.Territs[territs[0]].Troops)
+	}
+	if state.Phase.Deploy == nil || state.Phase.Deploy.Reinforcements != reinforcements {
+		t.Errorf("phase changed to %+v", state.Phase)
+	}
+}
+
+func TestActionAfterGameOverFails(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	mustApply(t, state, m, &Action{Resign: &ResignAction{Player: "bob"}})
+	if state.Phase.GameOver == nil {
+		t.Fatalf("game continued in phase '%s'", state.Phase.Name())
+	}
+	_, err := state.ApplyAction(m, &Action{EndAttack: &EndPhaseAction{Player: "alice"}}, testTime)
+	if err == nil {
+		t.Fatal("action was accepted after the game ended")
+	}
+}
+
+func TestSameSeedDealsSameGame(t *testing.T) {
+	m := loadTestMap(t)
+	first, err := json.Marshal(newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := json.Marshal(newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("games with the same seed differ:\n%s\n%s", first, second)
+	}
+}
+
+func TestBlitzConquers(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	player := state.ActivePlayer
+	from, to := attackFrom(t, state, m)
+	state.Territs[from].Troops = 10
+	state.Territs[to].Troops = 3
+
+	state.SetRandomSource(NewScriptedDice(6, 6, 6, 1, 1, 6, 6, 6, 1).Source())
+	events := mustApply(t, state, m, &Action{Blitz: &BlitzAction{
+		AttackAction: AttackAction{Player: player, From: from, To: to},
+		Detail:       true,
+	}})
+	attack := events[0].Attack
+	if !attack.Blitz || !attack.Conquered || attack.RollCount != 2 || attack.DefenderLosses != 3 {
+		t.Errorf("expected 2 rolls to conquer, got %+v", attack)
+	}
+	if len(attack.Rolls) != 2 || len(attack.Rolls[1].DefenderDice) != 1 {
+		t.Errorf("expected the dice of both rolls, got %+v", attack.Rolls)
+	}
+	if state.Territs[to].Owner != player || state.Phase.Advance == nil {
+		t.Errorf("expected %s to advance into %s, got %s in %s", player, to, state.Territs[to].Owner, state.Phase.Name())
+	}
+
+	// A blitz needs troops to spare.
+	mustApply(t, state, m, &Action{Advance: &MoveAction{Player: player, From: from, To: to, Troops: 0}})
+	state.Territs[from].Troops = 2
+	blitz := &Action{Blitz: &BlitzAction{AttackAction: AttackAction{Player: player, From: from, To: to}, MinTroops: 2}}
+	if _, err := state.ApplyAction(m, blitz, testTime); err == nil {
+		t.Error("blitzed without troops to spare")
+	}
+}
+
+func TestResolveAgainstDefender(t *testing.T) {
+	tests := []struct {
+		attacker       []int
+		defender       []int
+		attackerLosses uint64
+		defenderLosses uint64
+	}{
+		{[]int{6, 5, 1}, []int{5, 4}, 0, 2},
+		{[]int{6, 3, 1}, []int{5, 4}, 1, 1},
+		{[]int{4, 4, 4}, []int{4, 4}, 2, 0},
+		{[]int{2}, []int{1, 1}, 0, 1},
+		{[]int{6, 6}, []int{6}, 1, 0},
+	}
+	for _, test := range tests {
+		attacker := DiceRoll{test.attacker}
+		defender := DiceRoll{test.defender}
+		attackerLosses, defenderLosses := attacker.ResolveAgainstDefender(&defender)
+		if attackerLosses != test.attackerLosses || defenderLosses != test.defenderLosses {
+			t.Errorf("%v against %v: expected losses %d/%d, got %d/%d",
+				test.attacker, test.defender,
+				test.attackerLosses, test.defenderLosses, attackerLosses, defenderLosses)
+		}
+	}
+}
+
+func TestAttackConquers(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	player := state.ActivePlayer
+	from, to := attackFrom(t, state, m)
+	state.Territs[from].Troops = 4
+	state.Territs[to].Troops = 1
+
+	state.SetRandomSource(NewScriptedDice(6, 2, 1, 5).Source())
+	events := mustApply(t, state, m, &Action{Attack: &AttackAction{Player: player, From: from, To: to}})
+	attack := events[0].Attack
+	if !attack.Conquered {
+		t.Fatal("expected the attack to conquer")
+	}
+	if len(attack.AttackerDice) != 3 || len(attack.DefenderDice) != 1 {
+		t.Errorf("expected 3 dice against 1, got %v against %v", attack.AttackerDice, attack.DefenderDice)
+	}
+	if state.Territs[to].Owner != player {
+		t.Errorf("expected %s to own %s, got %s", player, to, state.Territs[to].Owner)
+	}
+	if state.Phase.Advance == nil {
+		t.Errorf("expected advance phase, got '%s'", state.Phase.Name())
+	}
+}
+
+func TestAttackRepelled(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	player := state.ActivePlayer
+	from, to := attackFrom(t, state, m)
+	state.Territs[from].Troops = 3
+	state.Territs[to].Troops = 2
+
+	state.SetRandomSource(NewScriptedDice(3, 2, 6, 6).Source())
+	events := mustApply(t, state, m, &Action{Attack: &AttackAction{Player: player, From: from, To: to}})
+	attack := events[0].Attack
+	if attack.Conquered || attack.AttackerLosses != 2 || attack.DefenderLosses != 0 {
+		t.Errorf("expected the attacker to lose 2 troops, got %+v", attack.AttackRoll)
+	}
+	if state.Territs[from].Troops != 1 || state.Territs[to].Troops != 2 {
+		t.Errorf("expected 1 and 2 troops left, got %d and %d",
+			state.Territs[from].Troops, state.Territs[to].Troops)
+	}
+}
+
+func TestBlitzStopsAtMinTroops(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	player := state.ActivePlayer
+	from, to := attackFrom(t, state, m)
+	state.Territs[from].Troops = 10
+	state.Territs[to].Troops = 10
+
+	// The attacker loses every roll: 3 dice, then 3, then only 1 die once 6
+	// troops are left.
+	state.SetRandomSource(NewScriptedDice(1, 1, 1, 6, 6, 1, 1, 1, 6, 6, 1, 6, 6).Source())
+	events := mustApply(t, state, m, &Action{Blitz: &BlitzAction{
+		AttackAction: AttackAction{Player: player, From: from, To: to},
+		MinTroops:    5,
+	}})
+	if troops := state.Territs[from].Troops; troops != 5 {
+		t.Errorf("expected blitz to stop at 5 troops, got %d", troops)
+	}
+	if attack := events[0].Attack; attack.RollCount != 3 || attack.AttackerLosses != 5 {
+		t.Errorf("expected 3 rolls losing 5 troops, got %d rolls losing %d", attack.RollCount, attack.AttackerLosses)
+	}
+	if attack := events[0].Attack; len(attack.AttackerDice) != 0 || len(attack.DefenderDice) != 0 {
+		t.Errorf("expected no dice for the whole blitz, got %v against %v", attack.AttackerDice, attack.DefenderDice)
+	}
+}
+
+func TestEventsAreNumbered(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	attackFrom(t, state, m)
+	seq := state.Seq
+	events := mustApply(t, state, m, &Action{EndAttack: &EndPhaseAction{Player: state.ActivePlayer}})
+	if len(events) == 0 {
+		t.Fatal("expected events")
+	}
+	for _, event := range events {
+		seq++
+		if event.Seq != seq {
+			t.Errorf("expected event %d, got %d", seq, event.Seq)
+		}
+	}
+	if state.Seq != seq {
+		t.Errorf("expected the game to be at %d, got %d", seq, state.Seq)
+	}
+}
+
+func TestSettingsSetTheRules(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.MaxPlayers = 2
+	settings.StartingTroops = 5
+	settings.MinReinforcements = 7
+	state := newTestState(t, m, settings, "alice", "bob")
+
+	for name, territ := range state.Territs {
+		if territ.Troops != settings.StartingTroops {
+			t.Errorf("expected %s to start with %d troops, got %d", name, settings.StartingTroops, territ.Troops)
+		}
+	}
+	if state.Phase.Deploy == nil || state.Phase.Deploy.Reinforcements < settings.MinReinforcements {
+		t.Errorf("expected at least %d reinforcements, got %+v", settings.MinReinforcements, state.Phase)
+	}
+
+	lobby := NewGameState(settings, 1)
+	for _, player := range []string{"alice", "bob"} {
+		mustApply(t, &lobby, m, &Action{JoinGame: &JoinGameAction{Player: player}})
+	}
+	if _, err := lobby.ApplyAction(m, &Action{JoinGame: &JoinGameAction{Player: "carol"}}, testTime); err == nil {
+		t.Error("joined a full game")
+	}
+}
+
+// playTurn plays the rest of the active player's turn at the given time.
+func playTurn(t *testing.T, state *GameState, m *Map, now time.Time) {
+	t.Helper()
+	player := state.ActivePlayer
+	for state.ActivePlayer == player {
+		if _, err := state.ApplyAction(m, state.autoPlayAction(m), now); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// timeOut times out the active player's turn at the given time.
+func timeOut(t *testing.T, state *GameState, m *Map, now time.Time) []*Event {
+	t.Helper()
+	events, err := state.ApplyAction(m, &Action{Timeout: &TimeoutAction{Player: state.ActivePlayer}}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestTimeoutPlaysRestOfTurn(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.TurnSeconds = 60
+	state := newTestState(t, m, settings, "alice", "bob")
+	player := state.ActivePlayer
+	if state.TurnDeadline == nil || !state.TurnDeadline.Equal(testTime.Add(time.Minute)) {
+		t.Fatalf("expected the turn to end at %v, got %v", testTime.Add(time.Minute), state.TurnDeadline)
+	}
+
+	countTroops := func() uint64 {
+		var troops uint64
+		for _, territ := range state.Territs {
+			troops += territ.Troops
+		}
+		return troops
+	}
+	troops := countTroops() + state.Phase.Deploy.Reinforcements
+
+	timeout := &Action{Timeout: &TimeoutAction{Player: player}}
+	if _, err := state.ApplyAction(m, timeout, testTime.Add(time.Minute-time.Second)); err == nil {
+		t.Error("timed out a turn before its deadline")
+	}
+	events := timeOut(t, state, m, testTime.Add(time.Minute))
+	if events[0].Timeout == nil || events[0].Timeout.Player != player || events[0].Timeout.Abandoned {
+		t.Errorf("expected %s to time out, got %+v", player, events[0])
+	}
+	if state.ActivePlayer == player || state.Phase.Deploy == nil {
+		t.Errorf("expected the next player's turn, got %s in %s", state.ActivePlayer, state.Phase.Name())
+	}
+	if timeouts := state.findPlayer(player).Timeouts; timeouts != 1 {
+		t.Errorf("expected 1 timeout, got %d", timeouts)
+	}
+	// The reinforcements were placed rather than lost.
+	if countTroops() != troops {
+		t.Errorf("expected %d troops after deploying, got %d", troops, countTroops())
+	}
+}
+
+func TestTimeBankPaysForSlowTurns(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.TurnSeconds = 60
+	settings.TimeBankSeconds = 30
+	state := newTestState(t, m, settings, "alice", "bob")
+	player := state.ActivePlayer
+	if !state.TurnDeadline.Equal(testTime.Add(90 * time.Second)) {
+		t.Fatalf("expected the time bank to extend the turn, got %v", state.TurnDeadline)
+	}
+
+	// Taking 75 seconds uses 15 of the 30 banked.
+	playTurn(t, state, m, testTime.Add(75*time.Second))
+	if bank := state.findPlayer(player).TimeBank; bank != 15*time.Second {
+		t.Errorf("expected 15s left in the bank, got %v", bank)
+	}
+	next := testTime.Add(2 * time.Minute)
+	playTurn(t, state, m, next)
+	if !state.TurnDeadline.Equal(next.Add(75 * time.Second)) {
+		t.Errorf("expected the turn to end at %v, got %v", next.Add(75*time.Second), state.TurnDeadline)
+	}
+
+	// Timing out empties the bank.
+	timeOut(t, state, m, next.Add(75*time.Second))
+	if bank := state.findPlayer(player).TimeBank; bank != 0 {
+		t.Errorf("expected the bank to be empty, got %v", bank)
+	}
+}
+
+func TestPlayersWhoKeepTimingOutAbandon(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.TurnSeconds = 60
+	settings.AbandonAfter = 2
+	state := newTestState(t, m, settings, "alice", "bob")
+	player := state.ActivePlayer
+
+	now := testTime
+	for i := 0; i < 2; i++ {
+		now = now.Add(time.Minute)
+		events := timeOut(t, state, m, now)
+		if abandoned := events[0].Timeout.Abandoned; abandoned != (i == 1) {
+			t.Errorf("timeout %d: expected abandoned to be %v", i+1, i == 1)
+		}
+		playTurn(t, state, m, now)
+	}
+	if !state.findPlayer(player).Abandoned {
+		t.Fatalf("expected %s to have abandoned", player)
+	}
+	// Abandoned players get no time, but come back by playing a turn.
+	if !state.TurnDeadline.Equal(now) {
+		t.Errorf("expected the turn to end immediately, got %v", state.TurnDeadline)
+	}
+	playTurn(t, state, m, now)
+	if state.findPlayer(player).Abandoned || state.findPlayer(player).Timeouts != 0 {
+		t.Errorf("expected %s to be back, got %+v", player, state.findPlayer(player))
+	}
+}
+
+func TestTimeoutPassesTurnThatCannotBePlayed(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.TurnSeconds = 60
+	state := newTestState(t, m, settings, "alice", "bob")
+	player := state.ActivePlayer
+	// Nothing can be deployed, so automatic play fails.
+	state.Phase.Deploy.Reinforcements = 0
+	troops := state.Players[0].Troops
+
+	events, err := state.ApplyAction(m, &Action{Timeout: &TimeoutAction{Player: player}}, testTime.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !events[0].Timeout.Passed {
+		t.Error("expected the turn to be passed")
+	}
+	if state.ActivePlayer == player || state.Phase.Deploy == nil {
+		t.Errorf("expected the next player's deploy, got %s in '%s'", state.ActivePlayer, state.Phase.Name())
+	}
+	if state.Players[0].Troops != troops {
+		t.Errorf("troops changed from %d to %d", troops, state.Players[0].Troops)
+	}
+	if timeouts := state.findPlayer(player).Timeouts; timeouts != 1 {
+		t.Errorf("expected 1 timeout, got %d", timeouts)
+	}
+	if state.actions != 4 || events[len(events)-1].Seq != state.Seq {
+		t.Errorf("expected the timeout to be counted as an action")
+	}
+}
+
+func TestResignHandsTerritoriesToHeir(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob", "carol")
+	active := state.ActivePlayer
+	heir := state.Players[0].Name
+	if heir == active {
+		heir = state.Players[1].Name
+	}
+	territs := ownedTerrits(state, active)
+
+	events := mustApply(t, state, m, &Action{Resign: &ResignAction{Player: active, Heir: heir}})
+	if resigned := events[0].Resigned; resigned == nil || resigned.Player != active || resigned.Kicked {
+		t.Errorf("expected %s to resign, got %+v", active, events[0])
+	}
+	for _, territ := range territs {
+		if owner := state.Territs[territ].Owner; owner != heir {
+			t.Errorf("expected %s to go to %s, got %s", territ, heir, owner)
+		}
+	}
+	if player := state.findPlayer(active); !player.Resigned || !player.Eliminated {
+		t.Errorf("expected %s to be out of the game, got %+v", active, player)
+	}
+	if state.ActivePlayer == active || state.Phase.GameOver != nil {
+		t.Errorf("expected the game to go on without %s, got %s in %s", active, state.ActivePlayer, state.Phase.Name())
+	}
+}
+
+func TestResignWithoutHeirLeavesTerritoriesNeutral(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob", "carol")
+	territs := ownedTerrits(state, "carol")
+	mustApply(t, state, m, &Action{Resign: &ResignAction{Player: "carol"}})
+	for _, territ := range territs {
+		if owner := state.Territs[territ].Owner; owner != NeutralOwner {
+			t.Errorf("expected %s to be neutral, got %s", territ, owner)
+		}
+	}
+
+	// The last player standing wins.
+	mustApply(t, state, m, &Action{Resign: &ResignAction{Player: "bob"}})
+	if state.Phase.GameOver == nil || state.Phase.GameOver.Winner != "alice" {
+		t.Errorf("expected alice to win, got %+v", state.Phase)
+	}
+}
+
+func TestOnlyAdminKicks(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob", "carol")
+	for _, kick := range []*KickAction{
+		{Player: "bob", Target: "carol"},
+		{Player: "alice", Target: "alice"},
+		{Player: "alice", Target: "dave"},
+	} {
+		if _, err := state.ApplyAction(m, &Action{Kick: kick}, testTime); err == nil {
+			t.Errorf("%s kicked %s", kick.Player, kick.Target)
+		}
+	}
+	events := mustApply(t, state, m, &Action{Kick: &KickAction{Player: "alice", Target: "carol"}})
+	if resigned := events[0].Resigned; resigned == nil || resigned.Player != "carol" || !resigned.Kicked {
+		t.Errorf("expected carol to be kicked, got %+v", events[0])
+	}
+}
+
+func TestKickPassesToActivePlayer(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob", "carol")
+	mustApply(t, state, m, &Action{Resign: &ResignAction{Player: "alice"}})
+
+	_, err := state.ApplyAction(m, &Action{Kick: &KickAction{Player: "alice", Target: "carol"}}, testTime)
+	if err == nil {
+		t.Fatal("resigned admin kicked a player")
+	}
+	mustApply(t, state, m, &Action{Kick: &KickAction{Player: "bob", Target: "carol"}})
+	if carol := state.findPlayer("carol"); !carol.Resigned {
+		t.Error("expected carol to be kicked")
+	}
+}
+
+func TestKickPassesOverBots(t *testing.T) {
+	m := loadTestMap(t)
+	state := NewGameState(DefaultGameSettings("hk"), 1)
+	for _, action := range []*Action{
+		{JoinGame: &JoinGameAction{Player: "alice"}},
+		{JoinGame: &JoinGameAction{Player: "random bot 1", Bot: RandomBotKind}},
+		{JoinGame: &JoinGameAction{Player: "carol"}},
+		{JoinGame: &JoinGameAction{Player: "dave"}},
+		{StartGame: &StartGameAction{Player: "alice"}},
+		{Resign: &ResignAction{Player: "alice"}},
+	} {
+		mustApply(t, &state, m, action)
+	}
+	mustApply(t, &state, m, &Action{Kick: &KickAction{Player: "carol", Target: "dave"}})
+	if dave := state.findPlayer("dave"); !dave.Resigned {
+		t.Error("expected dave to be kicked")
+	}
+}
+
+// newTeamState starts a team game in which each player is on the given team.
+func newTeamState(t *testing.T, m *Map, settings GameSettings, teams ...[2]string) *GameState {
+	t.Helper()
+	settings.Teams = true
+	state := NewGameState(settings, 1)
+	for _, team := range teams {
+		mustApply(t, &state, m, &Action{JoinGame: &JoinGameAction{Player: team[0]}})
+		mustApply(t, &state, m, &Action{ChooseTeam: &TeamAction{Player: team[0], Team: team[1]}})
+	}
+	mustApply(t, &state, m, &Action{StartGame: &StartGameAction{Player: teams[0][0]}})
+	return &state
+}
+
+func TestChooseTeam(t *testing.T) {
+	m := loadTestMap(t)
+	state := NewGameState(DefaultGameSettings("hk"), 1)
+	for _, player := range []string{"alice", "bob"} {
+		mustApply(t, &state, m, &Action{JoinGame: &JoinGameAction{Player: player}})
+	}
+	choose := func(player string, target string, team string) error {
+		_, err := state.ApplyAction(m, &Action{ChooseTeam: &TeamAction{Player: player, Target: target, Team: team}}, testTime)
+		return err
+	}
+	if err := choose("alice", "", "red"); err == nil {
+		t.Error("chose a team in a game without teams")
+	}
+
+	state.Settings.Teams = true
+	if err := choose("bob", "alice", "red"); err == nil {
+		t.Error("bob chose alice's team")
+	}
+	if err := choose("alice", "bob", "red"); err != nil {
+		t.Errorf("admin failed to choose bob's team: %v", err)
+	}
+	if err := choose("alice", "", "red"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := state.ApplyAction(m, &Action{StartGame: &StartGameAction{Player: "alice"}}, testTime); err == nil {
+		t.Error("started a game with only one team")
+	}
+}
+
+func TestTeamWinsTogether(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTeamState(t, m, DefaultGameSettings("hk"), [2]string{"alice", "red"}, [2]string{"bob", "red"}, [2]string{"carol", "blue"})
+	mustApply(t, state, m, &Action{Resign: &ResignAction{Player: "bob"}})
+	if state.Phase.GameOver != nil {
+		t.Fatalf("game ended while both teams were left: %+v", state.Phase.GameOver)
+	}
+	mustApply(t, state, m, &Action{Resign: &ResignAction{Player: "carol"}})
+	gameOver := state.Phase.GameOver
+	if gameOver == nil || gameOver.Team != "red" {
+		t.Fatalf("expected red to win, got %+v", state.Phase)
+	}
+	// Eliminated teammates win too.
+	if len(gameOver.Winners) != 2 || gameOver.Winners[0] != "alice" || gameOver.Winners[1] != "bob" {
+		t.Errorf("expected alice and bob to win, got %v", gameOver.Winners)
+	}
+}
+
+func TestNoFriendlyFire(t *testing.T) {
+	m := loadTestMap(t)
+	for _, noFriendlyFire := range []bool{false, true} {
+		settings := DefaultGameSettings("hk")
+		settings.NoFriendlyFire = noFriendlyFire
+		state := newTeamState(t, m, settings,
+			[2]string{"alice", "red"}, [2]string{"bob", "red"}, [2]string{"carol", "blue"}, [2]string{"dave", "blue"})
+		player := state.ActivePlayer
+		from, to := attackFrom(t, state, m)
+		state.Territs[from].Troops = 4
+		for _, teammate := range state.Players {
+			if teammate.Name != player && teammate.Team == state.findPlayer(player).Team {
+				state.Territs[to].Owner = teammate.Name
+			}
+		}
+
+		_, err := state.ApplyAction(m, &Action{Attack: &AttackAction{Player: player, From: from, To: to}}, testTime)
+		if noFriendlyFire && err == nil {
+			t.Error("attacked a teammate without friendly fire")
+		} else if !noFriendlyFire && err != nil {
+			t.Errorf("failed to attack a teammate with friendly fire: %v", err)
+		}
+	}
+}
+
+func TestTeamNamedAfterPlayerIsAnotherSide(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.Teams = true
+	state := NewGameState(settings, 1)
+	for _, action := range []*Action{
+		{JoinGame: &JoinGameAction{Player: "alice"}},
+		{JoinGame: &JoinGameAction{Player: "bob"}},
+		{JoinGame: &JoinGameAction{Player: "carol"}},
+		{ChooseTeam: &TeamAction{Player: "alice", Team: "carol"}},
+		{ChooseTeam: &TeamAction{Player: "bob", Team: "carol"}},
+	} {
+		mustApply(t, &state, m, action)
+	}
+	// Carol plays alone against the team named after her.
+	mustApply(t, &state, m, &Action{StartGame: &StartGameAction{Player: "alice"}})
+	if state.calculateStats(m) {
+		t.Error("game is over as soon as it started")
+	}
+}
+
+func TestNeutralPlayerIsDealtTerritories(t *testing.T) {
+	m := loadTestMap(t)
+	settings := DefaultGameSettings("hk")
+	settings.NeutralPlayer = true
+	settings.NeutralTroops = 2
+	state := newTestState(t, m, settings, "alice", "bob")
+
+	neutral := ownedTerrits(state, NeutralOwner)
+	// Territories are dealt round the players, so the neutral owner's share
+	// may be one short.
+	if len(neutral) != len(m.Territs)/3 && len(neutral) != (len(m.Territs)+2)/3 {
+		t.Errorf("expected a third of %d territories to be neutral, got %d", len(m.Territs), len(neutral))
+	}
+	for _, territ := range neutral {
+		if troops := state.Territs[territ].Troops; troops != 2 {
+			t.Errorf("expected 2 troops in %s, got %d", territ, troops)
+		}
+	}
+	for _, player := range state.Players {
+		if player.Territories != uint64(len(ownedTerrits(state, player.Name))) {
+			t.Errorf("expected %s's territories to leave out neutral ones, got %d", player.Name, player.Territories)
+		}
+	}
+}
+
+func TestMapHoldsNeutralTerritories(t *testing.T) {
+	m := loadTestMap(t)
+	var held string
+	for name := range m.Territs {
+		held = name
+		break
+	}
+	m.Territs[held].Neutral = 4
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	if territ := state.Territs[held]; territ.Owner != NeutralOwner || territ.Troops != 4 {
+		t.Errorf("expected %s to be neutral with 4 troops, got %+v", held, territ)
+	}
+	if neutral := ownedTerrits(state, NeutralOwner); len(neutral) != 1 {
+		t.Errorf("expected only %s to be neutral, got %v", held, neutral)
+	}
+}
+
+func TestConquerNeutralTerritory(t *testing.T) {
+	m := loadTestMap(t)
+	state := newTestState(t, m, DefaultGameSettings("hk"), "alice", "bob")
+	player := state.ActivePlayer
+	from, to := attackFrom(t, state, m)
+	state.Territs[from].Troops = 4
+	state.Territs[to].Owner = NeutralOwner
+	state.Territs[to].Troops = 1
+	state.calculateStats(m)
+	territories := state.findPlayer(player).Territories
+
+	state.SetRandomSource(NewScriptedDice(6, 6, 6, 1).Source())
+	events := mustApply(t, state, m, &Action{Attack: &AttackAction{Player: player, From: from, To: to}})
+	if attack := events[0].Attack; !attack.Conquered || attack.Defender != NeutralOwner {
+		t.Fatalf("expected to conquer a neutral territory, got %+v", attack)
+	}
+	if got := state.findPlayer(player).Territories; got != territories+1 {
+		t.Errorf("expected %d territories, got %d", territories+1, got)
+	}
+	if state.Phase.Advance == nil {
+		t.Errorf("expected advance phase, got %s", state.Phase.Name())
+	}
+}
+
+func TestJoinRejectsReservedNames(t *testing.T) {
+	m := loadTestMap(t)
+	state := NewGameState(DefaultGameSettings("hk"), 1)
+	for _, name := range []string{"", NeutralOwner} {
+		if _, err := state.ApplyAction(m, &Action{JoinGame: &JoinGameAction{Player: name}}, testTime); err == nil {
+			t.Errorf("player '%s' joined", name)
+		}
+	}
+	action := &Action{Deploy: &DeployAction{}}
+	if err := action.ActAs(""); err == nil {
+		t.Error("acted without a player name")
+	}
+}
@@ -5,4 +5,5 @@ go 1.16
 require (
 	github.com/gorilla/mux v1.8.0
 	github.com/gorilla/websocket v1.4.2
+	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871
 )
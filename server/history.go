@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNoLog = errors.New("game has no log")
+
+// LogHeader is the first line of a game log and holds everything needed to
+// recreate the game before any action was applied.
+type LogHeader struct {
+	MapId   string    `json:"map_id"`
+	Seed    int64     `json:"seed"`
+	Created time.Time `json:"created"`
+	// Settings is missing from logs written before games had settings.
+	Settings *GameSettings `json:"settings,omitempty"`
+}
+
+func (h *LogHeader) GameSettings() GameSettings {
+	if h.Settings == nil {
+		return DefaultGameSettings(h.MapId)
+	}
+	return *h.Settings
+}
+
+// LogEntry records one accepted action and the events it produced.
+type LogEntry struct {
+	Index  uint64    `json:"index"`
+	Turn   uint64    `json:"turn"`
+	Player string    `json:"player"`
+	Time   time.Time `json:"time"`
+	Action *Action   `json:"action"`
+	Events []*Event  `json:"events"`
+}
+
+// LogLine is a single line in a game log file. Exactly one field is set.
+type LogLine struct {
+	Header *LogHeader `json:"header,omitempty"`
+	Entry  *LogEntry  `json:"entry,omitempty"`
+}
+
+type GameLog struct {
+	Header  LogHeader
+	Entries []*LogEntry
+}
+
+func (entry LogEntry) RedactForPlayer(playerName string, fog *Fog) *LogEntry {
+	redactedEntry := LogEntry(entry)
+	if entry.Action != nil {
+		redactedEntry.Action = entry.Action.RedactForPlayer(playerName, fog)
+	}
+	redactedEntry.Events = nil
+	for _, event := range entry.Events {
+		redactedEntry.Events = append(redactedEntry.Events, event.RedactForPlayer(playerName, fog))
+	}
+	return &redactedEntry
+}
+
+// Touches returns true if any event in the entry involves the territory.
+func (entry *LogEntry) Touches(territ string) bool {
+	for _, event := range entry.Events {
+		switch {
+		case event.Deploy != nil:
+			if _, found := event.Deploy.Deployments[territ]; found {
+				return true
+			}
+		case event.Attack != nil:
+			if event.Attack.From == territ || event.Attack.To == territ {
+				return true
+			}
+		case event.Advance != nil:
+			if event.Advance.From == territ || event.Advance.To == territ {
+				return true
+			}
+		case event.Reinforce != nil:
+			if event.Reinforce.From == territ || event.Reinforce.To == territ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Filter returns the entries that match the territory and turn. An empty
+// territory or a zero turn matches everything.
+func (l *GameLog) Filter(territ string, turn uint64) []*LogEntry {
+	entries := []*LogEntry{}
+	for _, entry := range l.Entries {
+		if turn != 0 && entry.Turn != turn {
+			continue
+		}
+		if territ != "" && !entry.Touches(territ) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Replay rebuilds a game by applying every logged action to a fresh game
+// state. It fails if an action is rejected or produces different events than
+// the ones that were recorded.
+func (l *GameLog) Replay(m *Map) (*GameState, error) {
+	return l.replay(m, func(entry *LogEntry, state *GameState) {})
+}
+
+// RedactForPlayer returns the entries that match the territory and turn, each
+// redacted with what the player could see right after it was applied. That is
+// what the player saw of it at the time, so seeing a territory later doesn't
+// reveal its past. Once the game is over, everything is revealed.
+func (l *GameLog) RedactForPlayer(m *Map, playerName string, fog *Fog, territ string, turn uint64) ([]*LogEntry, error) {
+	fogs := make(map[*LogEntry]*Fog)
+	if fog != nil {
+		_, err := l.replay(m, func(entry *LogEntry, state *GameState) {
+			fogs[entry] = state.FogFor(m, playerName)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var redactedEntries []*LogEntry
+	for _, entry := range l.Filter(territ, turn) {
+		redactedEntries = append(redactedEntries, entry.RedactForPlayer(playerName, fogs[entry]))
+	}
+	return redactedEntries, nil
+}
+
+// replay applies every logged action to a fresh game state, and calls visit
+// with each entry once it is applied.
+func (l *GameLog) replay(m *Map, visit func(entry *LogEntry, state *GameState)) (*GameState, error) {
+	state := NewGameState(l.Header.GameSettings(), l.Header.Seed)
+	for _, entry := range l.Entries {
+		events, err := state.ApplyAction(m, entry.Action, entry.Time)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %v", entry.Index, err)
+		}
+		replayed, err := json.Marshal(events)
+		if err != nil {
+			return nil, err
+		}
+		recorded, err := json.Marshal(entry.Events)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(replayed, recorded) {
+			return nil, fmt.Errorf("entry %d: replayed events differ from the log", entry.Index)
+		}
+		visit(entry, &state)
+	}
+	return &state, nil
+}
+
+// Verify checks that a stored game matches the state rebuilt from its log.
+func (l *GameLog) Verify(m *Map, record *GameRecord) error {
+	state, err := l.Replay(m)
+	if err != nil {
+		return err
+	}
+	replayedRecord := NewGameRecord(state)
+	replayedRecord.Created = record.Created
+	replayed, err := json.Marshal(replayedRecord)
+	if err != nil {
+		return err
+	}
+	stored, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(replayed, stored) {
+		return fmt.Errorf("stored game does not match its log")
+	}
+	return nil
+}
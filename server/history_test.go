@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+)
+
+// attackFromAnywhere deploys the active player's reinforcements next to an
+// enemy and attacks it.
+func attackFromAnywhere(t *testing.T, game *Game) {
+	t.Helper()
+	player := game.state.ActivePlayer
+	for name, territ := range game.state.Territs {
+		if territ.Owner != player {
+			continue
+		}
+		for _, neighbour := range game.m.Territs[name].Neighbours {
+			if game.state.Territs[neighbour.Name].Owner == player {
+				continue
+			}
+			for _, action := range []*Action{
+				{Deploy: &DeployAction{Player: player, Deployments: map[string]uint64{name: game.state.Phase.Deploy.Reinforcements}}},
+				{Attack: &AttackAction{Player: player, From: name, To: neighbour.Name}},
+			} {
+				if _, err := game.applyActionLocked(action); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return
+		}
+	}
+	t.Fatal("no territory to attack from")
+}
+
+func TestVerifyReplaysLog(t *testing.T) {
+	m := loadTestMap(t)
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, err := NewTestGameHongKong(m, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.lock.Lock()
+	attackFromAnywhere(t, game)
+	game.lock.Unlock()
+
+	gameLog, err := store.LoadLog(game.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gameLog.Entries) != 5 {
+		t.Fatalf("expected 5 logged actions, got %d", len(gameLog.Entries))
+	}
+	records, err := store.LoadGames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := records[game.id]
+	if err := gameLog.Verify(m, record); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, territ := range record.State.Territs {
+		territ.Troops += 1
+		break
+	}
+	if err := gameLog.Verify(m, record); err == nil {
+		t.Error("tampered game passed verification")
+	}
+}
+
+func TestReplayRejectsChangedEvents(t *testing.T) {
+	m := loadTestMap(t)
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, err := NewTestGameHongKong(m, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gameLog, err := store.LoadLog(game.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The second player joined in blue, not red.
+	gameLog.Entries[1].Events[0].PlayerJoined.Color = "red"
+	if _, err := gameLog.Replay(m); err == nil {
+		t.Error("log with changed events was replayed")
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// SlowWatcherPolicy decides what happens to a subscriber whose queue is full.
+type SlowWatcherPolicy int
+
+const (
+	// DisconnectSlowWatchers closes the subscriber, so the client reconnects
+	// and catches up.
+	DisconnectSlowWatchers SlowWatcherPolicy = iota
+	// DropEventsForSlowWatchers discards the events that don't fit.
+	DropEventsForSlowWatchers
+)
+
+func ParseSlowWatcherPolicy(policy string) (SlowWatcherPolicy, error) {
+	switch policy {
+	case "disconnect":
+		return DisconnectSlowWatchers, nil
+	case "drop":
+		return DropEventsForSlowWatchers, nil
+	}
+	return 0, fmt.Errorf("unknown slow watcher policy '%s'", policy)
+}
+
+// Configured from flags in main.
+var watchQueueSize = 64
+var slowWatcherPolicy = DisconnectSlowWatchers
+
+// EncodedEvent is an event encoded for one subscriber.
+type EncodedEvent struct {
+	Seq  uint64
+	Data []byte
+}
+
+type Subscriber struct {
+	player string
+	// queue holds encoded events waiting to be sent.
+	queue chan *EncodedEvent
+	// done is closed when the hub disconnects the subscriber.
+	done    chan struct{}
+	closed  bool
+	dropped uint64
+}
+
+// Hub fans events out to the watchers of a game. Publishing never blocks:
+// each subscriber has a bounded queue, and slow subscribers are dealt with
+// according to the slow watcher policy.
+type Hub struct {
+	lock         sync.Mutex
+	subscribers  map[*Subscriber]bool
+	queueSize    int
+	policy       SlowWatcherPolicy
+	dropped      uint64
+	disconnected uint64
+}
+
+type HubStats struct {
+	Subscribers   int    `json:"subscribers"`
+	QueueDepth    int    `json:"queue_depth"`
+	MaxQueueDepth int    `json:"max_queue_depth"`
+	Dropped       uint64 `json:"dropped"`
+	Disconnected  uint64 `json:"disconnected"`
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		lock:        sync.Mutex{},
+		subscribers: make(map[*Subscriber]bool),
+		queueSize:   watchQueueSize,
+		policy:      slowWatcherPolicy,
+	}
+}
+
+func (h *Hub) Subscribe(player string) *Subscriber {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	sub := &Subscriber{
+		player: player,
+		queue:  make(chan *EncodedEvent, h.queueSize),
+		done:   make(chan struct{}),
+	}
+	h.subscribers[sub] = true
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.subscribers, sub)
+}
+
+// Publish queues the events for every subscriber, redacted for their player
+// and what the fog lets them see.
+func (h *Hub) Publish(events []*Event, fog func(player string) *Fog) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for sub := range h.subscribers {
+		subFog := fog(sub.player)
+		for _, event := range events {
+			data, err := json.Marshal(event.RedactForPlayer(sub.player, subFog))
+			if err != nil {
+				return err
+			}
+			if !h.enqueueLocked(sub, &EncodedEvent{Seq: event.Seq, Data: data}) {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// enqueueLocked returns false if the subscriber was disconnected.
+func (h *Hub) enqueueLocked(sub *Subscriber, event *EncodedEvent) bool {
+	select {
+	case sub.queue <- event:
+		return true
+	default:
+	}
+
+	switch h.policy {
+	case DropEventsForSlowWatchers:
+		sub.dropped += 1
+		h.dropped += 1
+		return true
+	default:
+		log.Printf("disconnecting slow watcher: player=%s", sub.player)
+		h.disconnectLocked(sub)
+		return false
+	}
+}
+
+func (h *Hub) disconnectLocked(sub *Subscriber) {
+	if !sub.closed {
+		sub.closed = true
+		close(sub.done)
+		h.disconnected += 1
+	}
+	delete(h.subscribers, sub)
+}
+
+func (h *Hub) Stats() HubStats {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	stats := HubStats{
+		Subscribers:  len(h.subscribers),
+		Dropped:      h.dropped,
+		Disconnected: h.disconnected,
+	}
+	for sub := range h.subscribers {
+		depth := len(sub.queue)
+		stats.QueueDepth += depth
+		if depth > stats.MaxQueueDepth {
+			stats.MaxQueueDepth = depth
+		}
+	}
+	return stats
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+)
+
+// newTestHub returns a hub whose subscribers can queue two events.
+func newTestHub(policy SlowWatcherPolicy) *Hub {
+	hub := NewHub()
+	hub.queueSize = 2
+	hub.policy = policy
+	return hub
+}
+
+func testEvents(count int) []*Event {
+	var events []*Event
+	for i := 0; i < count; i++ {
+		events = append(events, &Event{Deploy: &DeployAction{Player: "alice"}})
+	}
+	return events
+}
+
+// noFog lets every watcher see everything.
+func noFog(player string) *Fog {
+	return nil
+}
+
+func TestHubDropsEventsForSlowWatchers(t *testing.T) {
+	hub := newTestHub(DropEventsForSlowWatchers)
+	slow := hub.Subscribe("alice")
+	fast := hub.Subscribe("bob")
+	if err := hub.Publish(testEvents(2), noFog); err != nil {
+		t.Fatal(err)
+	}
+	<-fast.queue
+	<-fast.queue
+	if err := hub.Publish(testEvents(1), noFog); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(slow.queue) != 2 || slow.dropped != 1 {
+		t.Errorf("expected 2 events queued and 1 dropped, got %d and %d", len(slow.queue), slow.dropped)
+	}
+	if len(fast.queue) != 1 || fast.dropped != 0 {
+		t.Errorf("fast watcher lost events")
+	}
+	stats := hub.Stats()
+	if stats.Subscribers != 2 || stats.Dropped != 1 || stats.QueueDepth != 3 || stats.MaxQueueDepth != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHubDisconnectsSlowWatchers(t *testing.T) {
+	hub := newTestHub(DisconnectSlowWatchers)
+	slow := hub.Subscribe("alice")
+	if err := hub.Publish(testEvents(3), noFog); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-slow.done:
+	default:
+		t.Fatal("slow watcher was not disconnected")
+	}
+	// Publishing to nobody is fine.
+	if err := hub.Publish(testEvents(1), noFog); err != nil {
+		t.Fatal(err)
+	}
+	if stats := hub.Stats(); stats.Subscribers != 0 || stats.Disconnected != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
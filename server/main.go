@@ -1,68 +1,194 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
+	"expvar"
+	"flag"
 	"fmt"
+	"html/template"
 	"log"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
-	"text/template"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 )
 
+// historySize is the number of recent events kept for watchers that reconnect.
+const historySize = 256
+
 type Game struct {
-	lock      sync.Mutex
-	state     GameState
-	m         *Map
-	listeners []*Listener
+	lock    sync.Mutex
+	id      string
+	state   GameState
+	m       *Map
+	store   Store
+	hub     *Hub
+	history []*Event
+	created time.Time
+	// bots play for the players that are bots, created on their first turn.
+	bots        map[string]Bot
+	botsRunning bool
+	turnTimer   *time.Timer
 }
 
-type Listener struct {
-	player  string
-	channel chan []byte
+// NewGame creates a game in the lobby phase and starts its log.
+func NewGame(id string, settings GameSettings, m *Map, seed int64, store Store) (*Game, error) {
+	created := time.Now()
+	err := store.CreateLog(id, &LogHeader{
+		MapId:    settings.Map,
+		Seed:     seed,
+		Created:  created,
+		Settings: &settings,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Game{
+		lock:    sync.Mutex{},
+		id:      id,
+		state:   NewGameState(settings, seed),
+		m:       m,
+		store:   store,
+		hub:     NewHub(),
+		created: created,
+	}, nil
 }
 
-func (game *Game) addListener(player string, listener chan []byte) {
-	game.lock.Lock()
-	defer game.lock.Unlock()
-	game.listeners = append(game.listeners, &Listener{player, listener})
+// applyActionLocked applies the action to the game state, then records it in
+// the game's log and saves the resulting state.
+func (game *Game) applyActionLocked(action *Action) ([]*Event, error) {
+	entry := &LogEntry{
+		Index:  game.state.actions,
+		Turn:   game.state.Turn,
+		Player: action.player(),
+		Time:   time.Now(),
+		Action: action,
+	}
+	events, err := game.state.ApplyAction(game.m, action, entry.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	// AppendLog encodes the entry immediately, which matters because the
+	// snapshot event points into the live game state.
+	entry.Events = events
+	if err := game.store.AppendLog(game.id, entry); err != nil {
+		log.Printf("failed to log action for game=%s: %v", game.id, err)
+	}
+	game.saveLocked()
+	game.recordHistoryLocked(events)
+	game.scheduleTimeoutLocked()
+	return events, nil
+}
+
+// scheduleTimeoutLocked arms the turn timer for the current turn's deadline.
+func (game *Game) scheduleTimeoutLocked() {
+	if game.turnTimer != nil {
+		game.turnTimer.Stop()
+		game.turnTimer = nil
+	}
+	if game.state.TurnDeadline == nil {
+		return
+	}
+	deadline := *game.state.TurnDeadline
+	game.turnTimer = time.AfterFunc(time.Until(deadline), func() {
+		game.timeOutTurn(deadline)
+	})
 }
 
-func (game *Game) removeListener(listener chan []byte) {
+// timeOutTurn plays the rest of the active player's turn once its deadline
+// has passed.
+func (game *Game) timeOutTurn(deadline time.Time) {
 	game.lock.Lock()
 	defer game.lock.Unlock()
-	for idx := range game.listeners {
-		if game.listeners[idx].channel == listener {
-			game.listeners[idx] = game.listeners[len(game.listeners)-1]
-			game.listeners = game.listeners[:len(game.listeners)-1]
-			break
+	// This runs on the timer's goroutine, so a bug must not take down the
+	// server.
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("timing out game=%s panicked: %v", game.id, r)
 		}
+	}()
+	if game.state.TurnDeadline == nil || !game.state.TurnDeadline.Equal(deadline) {
+		// The turn ended before the timer fired.
+		return
 	}
+	player := game.state.ActivePlayer
+	events, err := game.applyActionLocked(&Action{Timeout: &TimeoutAction{Player: player}})
+	if err != nil {
+		log.Printf("failed to time out game=%s player=%s: %v", game.id, player, err)
+		return
+	}
+	if err := game.hub.Publish(events, game.fogLocked); err != nil {
+		log.Print("failed to notify watchers:", err)
+	}
+	game.wakeBotsLocked()
 }
 
-func (game *Game) notifyListenersLocked(events []*Event) error {
-	for idx := range game.listeners {
-		for _, event := range events {
-			data, err := json.Marshal(event.RedactForPlayer(game.listeners[idx].player))
-			if err != nil {
-				return err
-			}
-			game.listeners[idx].channel <- data
+// recordHistoryLocked keeps a copy of the most recent events.
+func (game *Game) recordHistoryLocked(events []*Event) {
+	for _, event := range events {
+		// Copy the event, since a snapshot points into the live game state.
+		data, err := json.Marshal(event)
+		if err != nil {
+			log.Printf("failed to record event for game=%s: %v", game.id, err)
+			continue
 		}
+		var copied Event
+		if err := json.Unmarshal(data, &copied); err != nil {
+			log.Printf("failed to record event for game=%s: %v", game.id, err)
+			continue
+		}
+		game.history = append(game.history, &copied)
+	}
+	if len(game.history) > historySize {
+		game.history = append([]*Event(nil), game.history[len(game.history)-historySize:]...)
+	}
+}
+
+// eventsSinceLocked returns the events after the sequence number, or false if
+// some of them are no longer kept.
+func (game *Game) eventsSinceLocked(seq uint64) ([]*Event, bool) {
+	if seq >= game.state.Seq {
+		return nil, true
+	}
+	if len(game.history) == 0 || game.history[0].Seq > seq+1 {
+		return nil, false
+	}
+	return game.history[seq+1-game.history[0].Seq:], true
+}
+
+// fogLocked returns what the player can see of the game.
+func (game *Game) fogLocked(player string) *Fog {
+	return game.state.FogFor(game.m, player)
+}
+
+// saveLocked persists the game. Failures are logged rather than returned,
+// since the action that triggered the save has already been applied.
+func (game *Game) saveLocked() {
+	record := NewGameRecord(&game.state)
+	record.Created = game.created
+	if err := game.store.SaveGame(game.id, record); err != nil {
+		log.Printf("failed to save game=%s: %v", game.id, err)
 	}
-	return nil
 }
 
 type Context struct {
-	lock  sync.Mutex
-	games map[string]*Game
-	maps  map[string]*Map
+	lock     sync.Mutex
+	games    map[string]*Game
+	maps     map[string]*Map
+	store    Store
+	accounts *Accounts
 }
 
 func (ctx *Context) findGame(id string) (*Game, bool) {
@@ -81,29 +207,127 @@ func (ctx *Context) findMap(id string) (*Map, bool) {
 
 var upgrader = websocket.Upgrader{}
 
-func NewContext() Context {
+// Watcher heartbeats, configured from flags in main. A watcher that sends
+// nothing, not even a pong, for watchIdleTimeout is dropped.
+var watchPingInterval = 30 * time.Second
+var watchIdleTimeout = 75 * time.Second
+var watchWriteTimeout = 10 * time.Second
+
+var reapedWatchers = expvar.NewInt("reaped_watchers")
+
+// isTimeout returns true if the error is a network timeout.
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+// writeWatchMessage writes to a watcher, giving up after watchWriteTimeout.
+func writeWatchMessage(c *websocket.Conn, data []byte) error {
+	c.SetWriteDeadline(time.Now().Add(watchWriteTimeout))
+	return c.WriteMessage(websocket.TextMessage, data)
+}
+
+// reapWatcher records a watcher that was dropped for going silent.
+func reapWatcher(gameId string, err error) {
+	reapedWatchers.Add(1)
+	log.Printf("reaped silent watcher: game=%s: %v", gameId, err)
+}
+
+func NewContext(store Store, accounts *Accounts) Context {
 	return Context{
-		lock:  sync.Mutex{},
-		games: make(map[string]*Game),
-		maps:  make(map[string]*Map),
+		lock:     sync.Mutex{},
+		games:    make(map[string]*Game),
+		maps:     make(map[string]*Map),
+		store:    store,
+		accounts: accounts,
+	}
+}
+
+// loadGames restores every stored game. Maps must be loaded first.
+func (ctx *Context) loadGames() error {
+	records, err := ctx.store.LoadGames()
+	if err != nil {
+		return err
 	}
+	ctx.lock.Lock()
+	defer ctx.lock.Unlock()
+	loaded := 0
+	for id, record := range records {
+		m, found := ctx.maps[record.MapId]
+		if !found {
+			log.Printf("skipping game=%s: map '%s' not found", id, record.MapId)
+			continue
+		}
+		state := record.GameState()
+		gameLog, err := ctx.store.LoadLog(id)
+		if err == nil {
+			if err := gameLog.Verify(m, record); err != nil {
+				log.Printf("game=%s does not match its log: %v", id, err)
+				if replayed, err := gameLog.Replay(m); err == nil {
+					// The log is the source of truth, e.g. when the server
+					// stopped between logging an action and saving the game.
+					log.Printf("restoring game=%s from its log", id)
+					state = *replayed
+				}
+			}
+		} else if err != ErrNoLog {
+			log.Printf("failed to load log for game=%s: %v", id, err)
+		}
+		game := &Game{
+			lock:    sync.Mutex{},
+			id:      id,
+			state:   state,
+			m:       m,
+			store:   ctx.store,
+			hub:     NewHub(),
+			created: record.Created,
+		}
+		if gameLog != nil {
+			if game.created.IsZero() {
+				game.created = gameLog.Header.Created
+			}
+			// Recover the recent events so that watchers can resume after a restart.
+			var events []*Event
+			for _, entry := range gameLog.Entries {
+				events = append(events, entry.Events...)
+			}
+			if len(events) > historySize {
+				events = events[len(events)-historySize:]
+			}
+			if len(events) > 0 && events[len(events)-1].Seq == state.Seq {
+				game.history = events
+			}
+		}
+		ctx.games[id] = game
+		game.lock.Lock()
+		game.scheduleTimeoutLocked()
+		game.wakeBotsLocked()
+		game.lock.Unlock()
+		loaded += 1
+	}
+	log.Printf("loaded %d games", loaded)
+	return nil
 }
 
-func getUser(w http.ResponseWriter, r *http.Request) (string, bool) {
-	cookie, err := r.Cookie("user")
-	if err != nil || cookie.Value == "" {
-		http.Redirect(w, r, fmt.Sprintf("/login?continue=%s", url.QueryEscape(r.URL.Path)), http.StatusFound)
-		return "", false
+const sessionCookie = "session"
+
+func (ctx *Context) getUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err == nil && cookie.Value != "" {
+		if user, found := ctx.accounts.Authenticate(cookie.Value); found {
+			return user, true
+		}
 	}
-	return cookie.Value, true
+	http.Redirect(w, r, fmt.Sprintf("/login?continue=%s", url.QueryEscape(r.URL.Path)), http.StatusFound)
+	return "", false
 }
 
 //go:embed game.html
 var gameTmplStr string
 var gameTmpl = template.Must(template.New("game").Parse(gameTmplStr))
 
-func staticGamePage(w http.ResponseWriter, r *http.Request) {
-	user, found := getUser(w, r)
+func (ctx *Context) staticGamePage(w http.ResponseWriter, r *http.Request) {
+	user, found := ctx.getUser(w, r)
 	if !found {
 		return
 	}
@@ -125,45 +349,112 @@ func staticGamePage(w http.ResponseWriter, r *http.Request) {
 var indexTmplStr string
 var indexTmpl = template.Must(template.New("index").Parse(indexTmplStr))
 
-func getCreate(w http.ResponseWriter, r *http.Request) {
-	_, found := getUser(w, r)
+func (ctx *Context) getCreate(w http.ResponseWriter, r *http.Request) {
+	_, found := ctx.getUser(w, r)
 	if !found {
 		return
 	}
-	indexTmpl.Execute(w, nil)
+
+	type MapOption struct {
+		Id   string
+		Name string
+	}
+	type PageData struct {
+		Maps []MapOption
+	}
+
+	data := &PageData{}
+	ctx.lock.Lock()
+	for id, m := range ctx.maps {
+		data.Maps = append(data.Maps, MapOption{Id: id, Name: m.Name})
+	}
+	ctx.lock.Unlock()
+	sort.Slice(data.Maps, func(i int, j int) bool {
+		return data.Maps[i].Name < data.Maps[j].Name
+	})
+	indexTmpl.Execute(w, data)
 }
 
 //go:embed login.html
 var loginTmplStr string
 var loginTmpl = template.Must(template.New("login").Parse(loginTmplStr))
 
-func getLogin(w http.ResponseWriter, r *http.Request) {
+func renderLogin(w http.ResponseWriter, r *http.Request, loginErr error) {
 	type PageData struct {
 		Redirect string
+		Error    string
 	}
-	loginTmpl.Execute(w,
-		&PageData{
-			Redirect: r.URL.Query().Get("continue"),
-		},
-	)
+	data := &PageData{
+		Redirect: continuePath(r),
+	}
+	if loginErr != nil {
+		data.Error = loginErr.Error()
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+	loginTmpl.Execute(w, data)
 }
 
-func postLogin(w http.ResponseWriter, r *http.Request) {
-	username := r.FormValue("username")
-	if username == "" {
-		w.Write([]byte(`Error`))
+// continuePath returns where to go after logging in. Only paths on this site
+// are allowed, so a link can't send the user elsewhere once they log in.
+func continuePath(r *http.Request) string {
+	path := r.URL.Query().Get("continue")
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+	return path
+}
+
+func getLogin(w http.ResponseWriter, r *http.Request) {
+	renderLogin(w, r, nil)
+}
+
+// startSession logs the user in and redirects to where they were going.
+func (ctx *Context) startSession(w http.ResponseWriter, r *http.Request, username string, password string) {
+	token, expires, err := ctx.accounts.Login(username, password)
+	if err != nil {
+		renderLogin(w, r, err)
 		return
 	}
 	http.SetCookie(w, &http.Cookie{
-		Name:  "user",
-		Value: username,
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
 	})
-	redirect := r.URL.Query().Get("continue")
-	if redirect != "" {
-		http.Redirect(w, r, redirect, http.StatusFound)
-	} else {
-		http.Redirect(w, r, "/", http.StatusFound)
+	http.Redirect(w, r, continuePath(r), http.StatusFound)
+}
+
+func (ctx *Context) postLogin(w http.ResponseWriter, r *http.Request) {
+	ctx.startSession(w, r, r.FormValue("username"), r.FormValue("password"))
+}
+
+func (ctx *Context) postRegister(w http.ResponseWriter, r *http.Request) {
+	username := r.FormValue("username")
+	password := r.FormValue("password")
+	// Registering a taken username is treated as logging in, so that it fails
+	// the same way as logging in to an account that doesn't exist.
+	if err := ctx.accounts.Register(username, password); err != nil && err != ErrUsernameTaken {
+		renderLogin(w, r, err)
+		return
 	}
+	ctx.startSession(w, r, username, password)
+}
+
+func (ctx *Context) postLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		if err := ctx.accounts.Logout(cookie.Value); err != nil {
+			log.Print("failed to log out: ", err)
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:   sessionCookie,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+	http.Redirect(w, r, "/login", http.StatusFound)
 }
 
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ123456789"
@@ -176,12 +467,89 @@ func RandStringBytes(n int) string {
 	return string(b)
 }
 
+// parseGameSettings reads the settings chosen in the create form. Fields left
+// blank keep their default.
+func parseGameSettings(r *http.Request, mapId string) (GameSettings, error) {
+	settings := DefaultGameSettings(mapId)
+	ints := map[string]*int{
+		"max_players":   &settings.MaxPlayers,
+		"hand_limit":    &settings.HandLimit,
+		"abandon_after": &settings.AbandonAfter,
+	}
+	for name, field := range ints {
+		if value := r.FormValue(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return settings, fmt.Errorf("invalid %s '%s'", name, value)
+			}
+			*field = parsed
+		}
+	}
+	uints := map[string]*uint64{
+		"starting_troops":       &settings.StartingTroops,
+		"reinforcement_divisor": &settings.ReinforcementDivisor,
+		"min_reinforcements":    &settings.MinReinforcements,
+		"spoils_red":            &settings.Spoils.Red,
+		"spoils_green":          &settings.Spoils.Green,
+		"spoils_blue":           &settings.Spoils.Blue,
+		"spoils_mixed":          &settings.Spoils.Mixed,
+		"spoils_cap":            &settings.Spoils.Cap,
+		"spoils_territory":      &settings.Spoils.Territory,
+		"turn_seconds":          &settings.TurnSeconds,
+		"time_bank_seconds":     &settings.TimeBankSeconds,
+		"neutral_troops":        &settings.NeutralTroops,
+	}
+	for name, field := range uints {
+		if value := r.FormValue(name); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return settings, fmt.Errorf("invalid %s '%s'", name, value)
+			}
+			*field = parsed
+		}
+	}
+	settings.FogOfWar = r.FormValue("fog_of_war") != ""
+	settings.NeutralPlayer = r.FormValue("neutral_player") != ""
+	settings.Teams = r.FormValue("teams") != ""
+	settings.NoFriendlyFire = r.FormValue("no_friendly_fire") != ""
+	settings.AlliedReinforce = r.FormValue("allied_reinforce") != ""
+	if value := r.FormValue("draft"); value != "" {
+		mode, err := ParseDraftMode(value)
+		if err != nil {
+			return settings, err
+		}
+		settings.Draft = mode
+	}
+	if value := r.FormValue("spoils_mode"); value != "" {
+		mode, err := ParseSpoilsMode(value)
+		if err != nil {
+			return settings, err
+		}
+		settings.Spoils.Mode = mode
+	}
+	return settings, settings.Validate()
+}
+
 func (ctx *Context) createGame(w http.ResponseWriter, r *http.Request) {
-	user, found := getUser(w, r)
+	user, found := ctx.getUser(w, r)
 	if !found {
 		return
 	}
 
+	mapId := r.FormValue("map")
+	m, found := ctx.findMap(mapId)
+	if !found {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`Unknown map`))
+		return
+	}
+	settings, err := parseGameSettings(r, mapId)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
 	ctx.lock.Lock()
 	defer ctx.lock.Unlock()
 	var newGameId string
@@ -192,19 +560,27 @@ func (ctx *Context) createGame(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	state := NewGameState("hk")
-	state.AddPlayer(user)
-	ctx.games[newGameId] = &Game{
-		lock:  sync.Mutex{},
-		state: state,
-		m:     ctx.maps["hk"],
+	game, err := NewGame(newGameId, settings, m, rand.Int63(), ctx.store)
+	if err != nil {
+		log.Print("failed to create game: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
+	game.lock.Lock()
+	_, err = game.applyActionLocked(&Action{JoinGame: &JoinGameAction{Player: user}})
+	game.lock.Unlock()
+	if err != nil {
+		log.Print("failed to join created game: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ctx.games[newGameId] = game
 
 	http.Redirect(w, r, fmt.Sprintf("/game/%s", newGameId), http.StatusFound)
 }
 
 func (ctx *Context) getGame(w http.ResponseWriter, r *http.Request) {
-	user, found := getUser(w, r)
+	user, found := ctx.getUser(w, r)
 	if !found {
 		return
 	}
@@ -219,7 +595,7 @@ func (ctx *Context) getGame(w http.ResponseWriter, r *http.Request) {
 	}
 	game.lock.Lock()
 	defer game.lock.Unlock()
-	data, err := json.Marshal(game.state.RedactForPlayer(user))
+	data, err := json.Marshal(game.state.RedactForPlayer(user, game.fogLocked(user)))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{ "error": "bad game state" }`))
@@ -229,8 +605,105 @@ func (ctx *Context) getGame(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
+// GameSummary describes a game in the lobby browser.
+type GameSummary struct {
+	Id      string    `json:"id"`
+	Map     string    `json:"map"`
+	MapName string    `json:"map_name"`
+	Phase   string    `json:"phase"`
+	Status  string    `json:"status"`
+	Players int       `json:"players"`
+	Creator string    `json:"creator"`
+	Created time.Time `json:"created"`
+	Winner  string    `json:"winner,omitempty"`
+}
+
+// Game statuses that the lobby browser can filter by.
+const (
+	StatusJoinable   = "joinable"
+	StatusFull       = "full"
+	StatusInProgress = "in_progress"
+	StatusFinished   = "finished"
+)
+
+func (game *Game) summary() *GameSummary {
+	game.lock.Lock()
+	defer game.lock.Unlock()
+	summary := &GameSummary{
+		Id:      game.id,
+		Map:     game.state.Map,
+		MapName: game.m.Name,
+		Phase:   game.state.Phase.Name(),
+		Players: len(game.state.Players),
+		Created: game.created,
+	}
+	if len(game.state.Players) > 0 {
+		summary.Creator = game.state.Players[0].Name
+	}
+	switch {
+	case game.state.Phase.Lobby != nil && len(game.state.Players) >= game.state.Settings.MaxPlayers:
+		// The game hasn't started, but nobody else can join it.
+		summary.Status = StatusFull
+	case game.state.Phase.Lobby != nil:
+		summary.Status = StatusJoinable
+	case game.state.Phase.GameOver != nil:
+		summary.Status = StatusFinished
+		summary.Winner = game.state.Phase.GameOver.Winner
+		if team := game.state.Phase.GameOver.Team; team != "" {
+			summary.Winner = fmt.Sprintf("team %s", team)
+		}
+	default:
+		summary.Status = StatusInProgress
+	}
+	return summary
+}
+
+func (ctx *Context) listGames(w http.ResponseWriter, r *http.Request) {
+	_, found := ctx.getUser(w, r)
+	if !found {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", StatusJoinable, StatusFull, StatusInProgress, StatusFinished:
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{ "error": "invalid status" }`))
+		return
+	}
+
+	ctx.lock.Lock()
+	var games []*Game
+	for _, game := range ctx.games {
+		games = append(games, game)
+	}
+	ctx.lock.Unlock()
+
+	summaries := []*GameSummary{}
+	for _, game := range games {
+		summary := game.summary()
+		if status == "" || summary.Status == status {
+			summaries = append(summaries, summary)
+		}
+	}
+	// Newest first.
+	sort.Slice(summaries, func(i int, j int) bool {
+		return summaries[i].Created.After(summaries[j].Created)
+	})
+	data, err := json.Marshal(summaries)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{ "error": "bad game list" }`))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
 func (ctx *Context) postGame(w http.ResponseWriter, r *http.Request) {
-	user, found := getUser(w, r)
+	user, found := ctx.getUser(w, r)
 	if !found {
 		return
 	}
@@ -251,23 +724,73 @@ func (ctx *Context) postGame(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(fmt.Sprintf(`{ "error": "%s" }`, err.Error())))
 		return
 	}
+	if err := action.ActAs(user); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(fmt.Sprintf(`{ "error": "%s" }`, err.Error())))
+		return
+	}
+
+	data, err := game.performAction(user, &action)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf(`{ "error": "%s" }`, err.Error())))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// addBot adds a bot to a game in the lobby. Only the game's admin may add bots.
+func (ctx *Context) addBot(w http.ResponseWriter, r *http.Request) {
+	user, found := ctx.getUser(w, r)
+	if !found {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	gameId := mux.Vars(r)["gameId"]
+	game, found := ctx.findGame(gameId)
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{ "error": "game not found" }`))
+		return
+	}
+
+	var request struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf(`{ "error": "%s" }`, err.Error())))
+		return
+	}
+	if _, err := NewBot(request.Kind); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf(`{ "error": "%s" }`, err.Error())))
+		return
+	}
 
 	game.lock.Lock()
 	defer game.lock.Unlock()
-	events, err := game.state.ApplyAction(game.m, &action)
+	if len(game.state.Players) == 0 || game.state.Players[0].Name != user {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{ "error": "only admin can add bots" }`))
+		return
+	}
+	// Usernames can't contain spaces, so bot names never clash with them.
+	var name string
+	for n := 1; name == "" || game.state.findPlayer(name) != nil; n++ {
+		name = fmt.Sprintf("%s bot %d", request.Kind, n)
+	}
+	events, err := game.applyActionLocked(&Action{JoinGame: &JoinGameAction{Player: name, Bot: request.Kind}})
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(fmt.Sprintf(`{ "error": "%s" }`, err.Error())))
 		return
 	}
-	if err := game.notifyListenersLocked(events); err != nil {
-		log.Print("failed to notify listeners:", err)
-	}
-	var redactedEvents []*Event
-	for _, event := range events {
-		redactedEvents = append(redactedEvents, event.RedactForPlayer(user))
+	if err := game.hub.Publish(events, game.fogLocked); err != nil {
+		log.Print("failed to notify watchers:", err)
 	}
-	data, err := json.Marshal(redactedEvents)
+	data, err := json.Marshal(events)
 	if err != nil {
 		log.Print("failed to encode result:", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -277,8 +800,136 @@ func (ctx *Context) postGame(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
+// performAction applies the action, notifies watchers and returns the
+// resulting events redacted for the user.
+func (game *Game) performAction(user string, action *Action) (json.RawMessage, error) {
+	if action.JoinGame != nil && action.JoinGame.Bot != "" {
+		return nil, fmt.Errorf("cannot join as a bot")
+	}
+	if action.Timeout != nil {
+		return nil, fmt.Errorf("only the server can time out a turn")
+	}
+	game.lock.Lock()
+	defer game.lock.Unlock()
+	events, err := game.applyActionLocked(action)
+	if err != nil {
+		return nil, err
+	}
+	if err := game.hub.Publish(events, game.fogLocked); err != nil {
+		log.Print("failed to notify watchers:", err)
+	}
+	game.wakeBotsLocked()
+	var redactedEvents []*Event
+	fog := game.fogLocked(user)
+	for _, event := range events {
+		redactedEvents = append(redactedEvents, event.RedactForPlayer(user, fog))
+	}
+	// Encode while holding the lock, since snapshots share data with the live
+	// game state.
+	data, err := json.Marshal(redactedEvents)
+	if err != nil {
+		log.Print("failed to encode result:", err)
+		return nil, fmt.Errorf("failed to encode result")
+	}
+	return data, nil
+}
+
+// ActionRequest is sent by watchers to perform an action over the websocket.
+type ActionRequest struct {
+	RequestId string `json:"request_id"`
+	Action    Action `json:"action"`
+}
+
+// ActionAck answers an ActionRequest with either the resulting events,
+// redacted for the watcher, or an error.
+type ActionAck struct {
+	RequestId string          `json:"request_id"`
+	Events    json.RawMessage `json:"events,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// WatchMessage is sent to watchers alongside events. Events always carry a
+// sequence number, while watch messages never do.
+type WatchMessage struct {
+	Ack *ActionAck `json:"ack,omitempty"`
+}
+
+// handleActionRequest performs an action sent over the websocket and returns
+// the encoded acknowledgement.
+func (game *Game) handleActionRequest(user string, message []byte) (data []byte) {
+	var request ActionRequest
+	// This runs in the websocket reader goroutine, which net/http doesn't
+	// recover, so a bug hit by one message must not take down the server.
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("action by user=%s in game=%s panicked: %v", user, game.id, r)
+			data, _ = json.Marshal(&WatchMessage{Ack: &ActionAck{RequestId: request.RequestId, Error: "internal error"}})
+		}
+	}()
+	ack := &ActionAck{}
+	if err := json.Unmarshal(message, &request); err != nil {
+		ack.Error = err.Error()
+	} else {
+		ack.RequestId = request.RequestId
+		if err := request.Action.ActAs(user); err != nil {
+			ack.Error = err.Error()
+		} else if events, err := game.performAction(user, &request.Action); err != nil {
+			ack.Error = err.Error()
+		} else {
+			ack.Events = events
+		}
+	}
+	data, err := json.Marshal(&WatchMessage{Ack: ack})
+	if err != nil {
+		log.Print("failed to encode ack: ", err)
+		return []byte(`{ "ack": { "error": "failed to encode ack" } }`)
+	}
+	return data
+}
+
+// parseSince parses the sequence number of the last event a watcher saw. It
+// returns nil if the watcher did not give one.
+func parseSince(sinceStr string) (*uint64, error) {
+	if sinceStr == "" {
+		return nil, nil
+	}
+	seq, err := strconv.ParseUint(sinceStr, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &seq, nil
+}
+
+// watch subscribes the user to the game's events. If since is set, it also
+// returns the encoded events after it, or a snapshot if they are no longer
+// kept. Subscribing under the game lock ensures that no event is missed or
+// sent twice.
+func (game *Game) watch(user string, since *uint64) ([]*EncodedEvent, *Subscriber, error) {
+	game.lock.Lock()
+	defer game.lock.Unlock()
+	var backlog []*EncodedEvent
+	if since != nil {
+		missed, found := game.eventsSinceLocked(*since)
+		if !found {
+			// Too many events were missed, start over from a snapshot.
+			missed = []*Event{{Seq: game.state.Seq, Snapshot: &game.state}}
+		}
+		fog := game.fogLocked(user)
+		for _, event := range missed {
+			// Encode while holding the lock, since snapshots share data with
+			// the live game state.
+			data, err := json.Marshal(event.RedactForPlayer(user, fog))
+			if err != nil {
+				return nil, nil, err
+			}
+			backlog = append(backlog, &EncodedEvent{Seq: event.Seq, Data: data})
+		}
+	}
+	return backlog, game.hub.Subscribe(user), nil
+}
+
 func (ctx *Context) watchGame(w http.ResponseWriter, r *http.Request) {
-	user, found := getUser(w, r)
+	user, found := ctx.getUser(w, r)
 	if !found {
 		return
 	}
@@ -290,6 +941,13 @@ func (ctx *Context) watchGame(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`{ "error": "game not found" }`))
 		return
 	}
+	since, err := parseSince(r.URL.Query().Get("since"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{ "error": "invalid since" }`))
+		return
+	}
 	c, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Print("upgrade failed: ", err)
@@ -297,38 +955,95 @@ func (ctx *Context) watchGame(w http.ResponseWriter, r *http.Request) {
 	}
 	defer c.Close()
 	log.Printf("new watcher: game=%s", gameId)
-	channel := make(chan []byte)
-	game.addListener(user, channel)
-	defer game.removeListener(channel)
 
-	closeChan := make(chan error)
+	backlog, sub, err := game.watch(user, since)
+	if err != nil {
+		log.Print("failed to encode missed event: ", err)
+		return
+	}
+	defer game.hub.Unsubscribe(sub)
+
+	for _, event := range backlog {
+		if err := writeWatchMessage(c, event.Data); err != nil {
+			log.Print("failed to send missed event: ", err)
+			return
+		}
+	}
+
+	// Anything the watcher sends, including pongs, proves it is still there.
+	c.SetReadDeadline(time.Now().Add(watchIdleTimeout))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(watchIdleTimeout))
+	})
+	pingTicker := time.NewTicker(watchPingInterval)
+	defer pingTicker.Stop()
+
+	// The reader performs actions and hands the acks to the writer below, since
+	// only one goroutine may write to the connection.
+	closeChan := make(chan error, 1)
+	acks := make(chan []byte)
+	done := make(chan struct{})
+	defer close(done)
 	go func() {
-		_, _, err := c.ReadMessage()
-		if err != nil {
-			closeChan <- err
-		} else {
-			// Got a message, that is not acceptable.
-			closeChan <- fmt.Errorf("client sent message, which is forbidden")
-			c.Close()
+		for {
+			_, message, err := c.ReadMessage()
+			if err != nil {
+				closeChan <- err
+				return
+			}
+			c.SetReadDeadline(time.Now().Add(watchIdleTimeout))
+			select {
+			case acks <- game.handleActionRequest(user, message):
+			case <-done:
+				return
+			}
 		}
 	}()
 
 	for {
 		select {
-		case event := <-channel:
-			err := c.WriteMessage(websocket.TextMessage, event)
+		case event := <-sub.queue:
+			err := writeWatchMessage(c, event.Data)
 			if err != nil {
 				if _, ok := err.(*websocket.CloseError); ok {
 					log.Print("watcher left")
+				} else if isTimeout(err) {
+					reapWatcher(gameId, err)
 				} else {
 					log.Print("failed to send event: ", err)
 				}
 				return
 			}
 
+		case ack := <-acks:
+			if err := writeWatchMessage(c, ack); err != nil {
+				if isTimeout(err) {
+					reapWatcher(gameId, err)
+				} else {
+					log.Print("failed to send ack: ", err)
+				}
+				return
+			}
+
+		case <-pingTicker.C:
+			if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(watchWriteTimeout)); err != nil {
+				if isTimeout(err) {
+					reapWatcher(gameId, err)
+				} else {
+					log.Print("failed to ping watcher: ", err)
+				}
+				return
+			}
+
+		case <-sub.done:
+			c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow"), time.Now().Add(watchWriteTimeout))
+			return
+
 		case err := <-closeChan:
 			if _, ok := err.(*websocket.CloseError); ok {
 				log.Print("watcher left")
+			} else if isTimeout(err) {
+				reapWatcher(gameId, err)
 			} else {
 				log.Print("connection unexpectedly closed: ", err)
 			}
@@ -337,6 +1052,195 @@ func (ctx *Context) watchGame(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// streamGameEvents sends the game's events as Server-Sent Events, for clients
+// that can't use websockets. Each event's id is its sequence number, so the
+// browser resumes with Last-Event-ID when it reconnects.
+func (ctx *Context) streamGameEvents(w http.ResponseWriter, r *http.Request) {
+	user, found := ctx.getUser(w, r)
+	if !found {
+		return
+	}
+	gameId := mux.Vars(r)["gameId"]
+	game, found := ctx.findGame(gameId)
+	if !found {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{ "error": "game not found" }`))
+		return
+	}
+	sinceStr := r.Header.Get("Last-Event-ID")
+	if sinceStr == "" {
+		sinceStr = r.URL.Query().Get("since")
+	}
+	since, err := parseSince(sinceStr)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{ "error": "invalid since" }`))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{ "error": "streaming not supported" }`))
+		return
+	}
+
+	backlog, sub, err := game.watch(user, since)
+	if err != nil {
+		log.Print("failed to encode missed event: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer game.hub.Unsubscribe(sub)
+	log.Printf("new event stream: game=%s", gameId)
+
+	// A watcher that stops reading would block a write forever, so every
+	// write gets a deadline. Missing it breaks the connection, which cancels
+	// the request.
+	conn, _ := r.Context().Value(connContextKey{}).(net.Conn)
+	if conn != nil {
+		defer conn.SetWriteDeadline(time.Time{})
+	}
+	send := func(format string, args ...interface{}) error {
+		if conn != nil {
+			conn.SetWriteDeadline(time.Now().Add(watchWriteTimeout))
+		}
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return r.Context().Err()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stop proxies from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	// Send the headers even if no event was missed.
+	if err := send(""); err != nil {
+		return
+	}
+	for _, event := range backlog {
+		if err := send("id: %d\ndata: %s\n\n", event.Seq, event.Data); err != nil {
+			return
+		}
+	}
+
+	// Comments keep idle connections from being closed by proxies.
+	pingTicker := time.NewTicker(watchPingInterval)
+	defer pingTicker.Stop()
+	for {
+		select {
+		case event := <-sub.queue:
+			if err := send("id: %d\ndata: %s\n\n", event.Seq, event.Data); err != nil {
+				log.Print("failed to send event: ", err)
+				return
+			}
+
+		case <-pingTicker.C:
+			if err := send(": ping\n\n"); err != nil {
+				return
+			}
+
+		case <-sub.done:
+			return
+
+		case <-r.Context().Done():
+			log.Print("event stream left")
+			return
+		}
+	}
+}
+
+// connContextKey stores the connection a request arrived on in its context,
+// so that streams can put deadlines on their writes.
+type connContextKey struct{}
+
+func saveConnInContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+// hubStats sums the watcher stats of every game, for /debug/vars.
+func (ctx *Context) hubStats() interface{} {
+	ctx.lock.Lock()
+	var games []*Game
+	for _, game := range ctx.games {
+		games = append(games, game)
+	}
+	ctx.lock.Unlock()
+
+	var total HubStats
+	for _, game := range games {
+		stats := game.hub.Stats()
+		total.Subscribers += stats.Subscribers
+		total.QueueDepth += stats.QueueDepth
+		if stats.MaxQueueDepth > total.MaxQueueDepth {
+			total.MaxQueueDepth = stats.MaxQueueDepth
+		}
+		total.Dropped += stats.Dropped
+		total.Disconnected += stats.Disconnected
+	}
+	return total
+}
+
+func (ctx *Context) getGameLog(w http.ResponseWriter, r *http.Request) {
+	user, found := ctx.getUser(w, r)
+	if !found {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	gameId := mux.Vars(r)["gameId"]
+	game, found := ctx.findGame(gameId)
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{ "error": "game not found" }`))
+		return
+	}
+	var turn uint64
+	if turnStr := r.URL.Query().Get("turn"); turnStr != "" {
+		var err error
+		turn, err = strconv.ParseUint(turnStr, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{ "error": "invalid turn" }`))
+			return
+		}
+	}
+	gameLog, err := ctx.store.LoadLog(gameId)
+	if err == ErrNoLog {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{ "error": "game has no log" }`))
+		return
+	} else if err != nil {
+		log.Printf("failed to load log for game=%s: %v", gameId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{ "error": "bad game log" }`))
+		return
+	}
+	game.lock.Lock()
+	fog := game.fogLocked(user)
+	game.lock.Unlock()
+	redactedEntries, err := gameLog.RedactForPlayer(game.m, user, fog, r.URL.Query().Get("territ"), turn)
+	if err != nil {
+		log.Printf("failed to replay log for game=%s: %v", gameId, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{ "error": "bad game log" }`))
+		return
+	}
+	data, err := json.Marshal(redactedEntries)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{ "error": "bad game log" }`))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
 func (ctx *Context) getMap(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	mapId := mux.Vars(r)["mapId"]
@@ -357,10 +1261,66 @@ func (ctx *Context) getMap(w http.ResponseWriter, r *http.Request) {
 }
 
 func main() {
+	dataDir := flag.String("data", "../data", "directory where games are stored, or empty to disable persistence")
+	mapsDir := flag.String("maps", "../maps", "directory to load maps from")
+	flag.IntVar(&watchQueueSize, "watch-queue", watchQueueSize, "number of events queued per watcher before it counts as slow")
+	flag.DurationVar(&watchPingInterval, "watch-ping", watchPingInterval, "how often to ping watchers")
+	flag.DurationVar(&watchIdleTimeout, "watch-idle-timeout", watchIdleTimeout, "how long a watcher may stay silent before it is dropped")
+	flag.DurationVar(&watchWriteTimeout, "watch-write-timeout", watchWriteTimeout, "how long a write to a watcher may take")
+	flag.DurationVar(&botMoveDelay, "bot-delay", botMoveDelay, "how long bots wait before each action")
+	debugAddr := flag.String("debug", "localhost:6060", "private address to serve /debug/vars on, or empty to disable")
+	slowWatchers := flag.String("slow-watchers", "disconnect", "what to do with slow watchers: 'disconnect' or 'drop' events")
+	flag.Parse()
+	policy, err := ParseSlowWatcherPolicy(*slowWatchers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slowWatcherPolicy = policy
+	// Watchers only answer pings, so they would be dropped between two pings.
+	if watchPingInterval <= 0 || watchPingInterval >= watchIdleTimeout {
+		log.Fatalf("-watch-ping %v must be positive and shorter than -watch-idle-timeout %v", watchPingInterval, watchIdleTimeout)
+	}
+	if flag.NArg() > 0 {
+		switch flag.Arg(0) {
+		case "validate-map":
+			os.Exit(validateMapCommand(*mapsDir, flag.Args()[1:]))
+		case "import-svg":
+			os.Exit(importSVGCommand(flag.Args()[1:]))
+		default:
+			log.Fatalf("unknown command '%s'", flag.Arg(0))
+		}
+	}
+
 	rand.Seed(time.Now().UnixNano())
-	ctx := NewContext()
-	ctx.maps["hk"] = NewTestMapHongKong()
-	ctx.games["1"] = NewTestGameHongKong(ctx.maps["hk"])
+	var store Store = NullStore{}
+	if *dataDir != "" {
+		fileStore, err := NewFileStore(*dataDir)
+		if err != nil {
+			log.Fatal("failed to open game store: ", err)
+		}
+		store = fileStore
+	}
+	accounts, err := LoadAccounts(store)
+	if err != nil {
+		log.Fatal("failed to load accounts: ", err)
+	}
+	ctx := NewContext(store, accounts)
+	maps, err := LoadMaps(*mapsDir)
+	if err != nil {
+		log.Fatal("failed to load maps: ", err)
+	}
+	ctx.maps = maps
+	log.Printf("loaded %d maps", len(maps))
+	if err := ctx.loadGames(); err != nil {
+		log.Fatal("failed to load games: ", err)
+	}
+	if _, found := ctx.games["1"]; !found && ctx.maps["hk"] != nil {
+		game, err := NewTestGameHongKong(ctx.maps["hk"], store)
+		if err != nil {
+			log.Fatal("failed to create test game: ", err)
+		}
+		ctx.games["1"] = game
+	}
 
 	staticFs := http.FileServer(http.Dir("../static"))
 	buildFs := http.FileServer(http.Dir("../dist"))
@@ -372,16 +1332,36 @@ func main() {
 	r.PathPrefix("/dist/").Handler(http.StripPrefix("/dist", buildFs))
 	r.PathPrefix("/src/").Handler(http.StripPrefix("/src", srcFs))
 
-	r.HandleFunc("/", getCreate).Methods(http.MethodGet)
+	r.HandleFunc("/", ctx.getCreate).Methods(http.MethodGet)
 	r.HandleFunc("/create", ctx.createGame).Methods(http.MethodPost)
 	r.HandleFunc("/login", getLogin).Methods(http.MethodGet)
-	r.HandleFunc("/login", postLogin).Methods(http.MethodPost)
-	r.HandleFunc("/game/{gameId}", staticGamePage).Methods(http.MethodGet)
+	r.HandleFunc("/login", ctx.postLogin).Methods(http.MethodPost)
+	r.HandleFunc("/register", ctx.postRegister).Methods(http.MethodPost)
+	r.HandleFunc("/logout", ctx.postLogout).Methods(http.MethodPost)
+	r.HandleFunc("/game/{gameId}", ctx.staticGamePage).Methods(http.MethodGet)
+
+	expvar.Publish("watchers", expvar.Func(ctx.hubStats))
+	if *debugAddr != "" {
+		debug := http.NewServeMux()
+		debug.Handle("/debug/vars", expvar.Handler())
+		go func() {
+			log.Fatal(http.ListenAndServe(*debugAddr, debug))
+		}()
+	}
 
 	s := r.PathPrefix("/api/v1/").Subrouter()
+	s.HandleFunc("/games", ctx.listGames).Methods(http.MethodGet)
 	s.HandleFunc("/game/{gameId}", ctx.getGame).Methods(http.MethodGet)
 	s.HandleFunc("/game/{gameId}", ctx.postGame).Methods(http.MethodPost)
+	s.HandleFunc("/game/{gameId}/bots", ctx.addBot).Methods(http.MethodPost)
 	s.HandleFunc("/game/{gameId}/watch", ctx.watchGame).Methods(http.MethodGet)
+	s.HandleFunc("/game/{gameId}/events", ctx.streamGameEvents).Methods(http.MethodGet)
+	s.HandleFunc("/game/{gameId}/log", ctx.getGameLog).Methods(http.MethodGet)
 	s.HandleFunc("/map/{mapId}", ctx.getMap).Methods(http.MethodGet)
-	log.Fatal(http.ListenAndServe(":8080", r))
+	server := &http.Server{
+		Addr:        ":8080",
+		Handler:     r,
+		ConnContext: saveConnInContext,
+	}
+	log.Fatal(server.ListenAndServe())
 }
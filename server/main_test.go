@@ -0,0 +1,755 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// newTestContext returns a context with the Hong Kong map and logged in
+// sessions for the users, keyed by name.
+func newTestContext(t *testing.T, users ...string) (*Context, map[string]string) {
+	t.Helper()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := LoadAccounts(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := NewContext(store, accounts)
+	ctx.maps["hk"] = loadTestMap(t)
+	sessions := make(map[string]string)
+	for _, user := range users {
+		if err := accounts.Register(user, "password"); err != nil {
+			t.Fatal(err)
+		}
+		token, _, err := accounts.Login(user, "password")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions[user] = token
+	}
+	return &ctx, sessions
+}
+
+// newTestGame starts a game between alice and bob.
+func newTestGame(t *testing.T, ctx *Context, id string) *Game {
+	t.Helper()
+	game, err := NewGame(id, DefaultGameSettings("hk"), ctx.maps["hk"], 1, ctx.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []*Action{
+		{JoinGame: &JoinGameAction{Player: "alice"}},
+		{JoinGame: &JoinGameAction{Player: "bob"}},
+		{StartGame: &StartGameAction{Player: "alice"}},
+	} {
+		if _, err := game.applyActionLocked(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx.games[id] = game
+	return game
+}
+
+func otherPlayer(player string) string {
+	if player == "alice" {
+		return "bob"
+	}
+	return "alice"
+}
+
+// postAction sends the action to the game as the user.
+func postAction(ctx *Context, session string, id string, action *Action) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(action)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/game/"+id, strings.NewReader(string(body)))
+	r.AddCookie(&http.Cookie{Name: sessionCookie, Value: session})
+	r = mux.SetURLVars(r, map[string]string{"gameId": id})
+	w := httptest.NewRecorder()
+	ctx.postGame(w, r)
+	return w
+}
+
+func TestPostGameActsAsUser(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice", "bob")
+	game := newTestGame(t, ctx, "acting")
+	active := game.state.ActivePlayer
+	var territ string
+	for name, territMut := range game.state.Territs {
+		if territMut.Owner == active {
+			territ = name
+		}
+	}
+	deploy := func(player string) *Action {
+		return &Action{Deploy: &DeployAction{
+			Player:      player,
+			Deployments: map[string]uint64{territ: game.state.Phase.Deploy.Reinforcements},
+		}}
+	}
+
+	if w := postAction(ctx, sessions[otherPlayer(active)], game.id, deploy(active)); w.Code != http.StatusForbidden {
+		t.Errorf("acting as another player returned %d %s", w.Code, w.Body.String())
+	}
+	if game.state.Phase.Deploy == nil {
+		t.Fatal("action of another player was applied")
+	}
+	// The logged in user is filled in when the action names no player.
+	if w := postAction(ctx, sessions[active], game.id, deploy("")); w.Code != http.StatusOK {
+		t.Errorf("deploying returned %d %s", w.Code, w.Body.String())
+	}
+	if game.state.Phase.Attack == nil {
+		t.Errorf("expected the attack phase, got %+v", game.state.Phase)
+	}
+}
+
+func TestPostGameNeedsSession(t *testing.T) {
+	ctx, _ := newTestContext(t, "alice", "bob")
+	game := newTestGame(t, ctx, "session")
+	w := postAction(ctx, "not a session", game.id, &Action{EndAttack: &EndPhaseAction{}})
+	if w.Code != http.StatusFound || !strings.HasPrefix(w.Header().Get("Location"), "/login") {
+		t.Errorf("expected a redirect to log in, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+}
+
+// postForm sends the username and password to the login or register form.
+func postForm(handler http.HandlerFunc, username string, password string) *httptest.ResponseRecorder {
+	form := url.Values{"username": {username}, "password": {password}}
+	r := httptest.NewRequest(http.MethodPost, "/login?continue=/game/1", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func TestRegisterStartsSession(t *testing.T) {
+	ctx, _ := newTestContext(t)
+	w := postForm(ctx.postRegister, "alice", "password")
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/game/1" {
+		t.Fatalf("expected a redirect to the game, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != sessionCookie || !cookies[0].HttpOnly {
+		t.Fatalf("expected an HTTP only session cookie, got %v", cookies)
+	}
+	if user, found := ctx.accounts.Authenticate(cookies[0].Value); !found || user != "alice" {
+		t.Errorf("session belongs to '%s'", user)
+	}
+
+	if w := postForm(ctx.postLogin, "alice", "wrong password"); w.Code != http.StatusUnauthorized {
+		t.Errorf("logging in with the wrong password returned %d", w.Code)
+	}
+	if w := postForm(ctx.postLogin, "alice", "password"); w.Code != http.StatusFound {
+		t.Errorf("logging in returned %d", w.Code)
+	}
+}
+
+func TestCreateGameOnMap(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice")
+	for mapId, expected := range map[string]int{"hk": http.StatusFound, "atlantis": http.StatusBadRequest} {
+		form := url.Values{"map": {mapId}}
+		r := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: sessionCookie, Value: sessions["alice"]})
+		w := httptest.NewRecorder()
+		ctx.createGame(w, r)
+		if w.Code != expected {
+			t.Errorf("creating a game on '%s' returned %d, expected %d", mapId, w.Code, expected)
+		}
+	}
+	if len(ctx.games) != 1 {
+		t.Fatalf("expected 1 game, got %d", len(ctx.games))
+	}
+	for _, game := range ctx.games {
+		if game.state.Map != "hk" {
+			t.Errorf("game is played on '%s'", game.state.Map)
+		}
+	}
+}
+
+// watchServer serves the game's watch websocket and event stream.
+func watchServer(ctx *Context) *httptest.Server {
+	r := mux.NewRouter()
+	r.HandleFunc("/api/v1/game/{gameId}/watch", ctx.watchGame)
+	r.HandleFunc("/api/v1/game/{gameId}/events", ctx.streamGameEvents)
+	return httptest.NewServer(r)
+}
+
+// dialWatch connects to the game's watch websocket as the user.
+func dialWatch(t *testing.T, server *httptest.Server, session string, id string, query string) *websocket.Conn {
+	t.Helper()
+	header := http.Header{}
+	header.Add("Cookie", sessionCookie+"="+session)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/game/" + id + "/watch" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// readEvent reads the next event from the websocket.
+func readEvent(t *testing.T, conn *websocket.Conn) *Event {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event Event
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatal(err)
+	}
+	return &event
+}
+
+func TestWatchResumesSince(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice", "bob")
+	game := newTestGame(t, ctx, "resume")
+	server := watchServer(ctx)
+	defer server.Close()
+
+	seq := game.state.Seq
+	conn := dialWatch(t, server, sessions["alice"], game.id, fmt.Sprintf("?since=%d", seq-2))
+	defer conn.Close()
+	for _, expected := range []uint64{seq - 1, seq} {
+		if event := readEvent(t, conn); event.Seq != expected {
+			t.Errorf("expected event %d, got %d", expected, event.Seq)
+		}
+	}
+
+	// Events that are no longer kept are replaced by a snapshot.
+	game.lock.Lock()
+	game.history = game.history[2:]
+	game.lock.Unlock()
+	conn = dialWatch(t, server, sessions["alice"], game.id, "?since=0")
+	defer conn.Close()
+	if event := readEvent(t, conn); event.Snapshot == nil || event.Seq != seq {
+		t.Errorf("expected a snapshot at %d, got %+v", seq, event)
+	}
+}
+
+// readAck reads from the websocket until the acknowledgement of a request.
+func readAck(t *testing.T, conn *websocket.Conn) *ActionAck {
+	t.Helper()
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var message WatchMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatal(err)
+		}
+		if message.Ack != nil {
+			return message.Ack
+		}
+	}
+}
+
+func TestWatchAcksActions(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice", "bob")
+	game := newTestGame(t, ctx, "acks")
+	server := watchServer(ctx)
+	defer server.Close()
+	active := game.state.ActivePlayer
+	conn := dialWatch(t, server, sessions[active], game.id, "")
+	defer conn.Close()
+
+	// The player is filled in, and errors are acked rather than dropping the
+	// connection.
+	request := ActionRequest{RequestId: "wrong", Action: Action{Deploy: &DeployAction{
+		Player:      otherPlayer(active),
+		Deployments: map[string]uint64{ownedTerrits(&game.state, active)[0]: 1},
+	}}}
+	if err := conn.WriteJSON(&request); err != nil {
+		t.Fatal(err)
+	}
+	if ack := readAck(t, conn); ack.RequestId != "wrong" || ack.Error == "" {
+		t.Errorf("expected an error, got %+v", ack)
+	}
+
+	request = ActionRequest{RequestId: "deploy", Action: Action{Deploy: &DeployAction{
+		Deployments: map[string]uint64{ownedTerrits(&game.state, active)[0]: 1},
+	}}}
+	if err := conn.WriteJSON(&request); err != nil {
+		t.Fatal(err)
+	}
+	ack := readAck(t, conn)
+	if ack.RequestId != "deploy" || ack.Error != "" {
+		t.Fatalf("expected the deployment to be acked, got %+v", ack)
+	}
+	var events []*Event
+	if err := json.Unmarshal(ack.Events, &events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) == 0 || events[0].Deploy == nil || events[0].Deploy.Player != active {
+		t.Errorf("expected the deployment, got %s", ack.Events)
+	}
+}
+
+// setWatchTimeouts shortens the watcher heartbeats for the test. Watchers
+// must be gone by the end of the test, see waitForWatchers.
+func setWatchTimeouts(t *testing.T, ping time.Duration, idle time.Duration) {
+	oldPing, oldIdle := watchPingInterval, watchIdleTimeout
+	watchPingInterval, watchIdleTimeout = ping, idle
+	t.Cleanup(func() {
+		watchPingInterval, watchIdleTimeout = oldPing, oldIdle
+	})
+}
+
+// waitForWatchers waits until the game has the given number of watchers.
+func waitForWatchers(t *testing.T, game *Game, watchers int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for game.hub.Stats().Subscribers != watchers {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d watchers, got %d", watchers, game.hub.Stats().Subscribers)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchReapsSilentWatchers(t *testing.T) {
+	setWatchTimeouts(t, 20*time.Millisecond, 200*time.Millisecond)
+	ctx, sessions := newTestContext(t, "alice", "bob")
+	game := newTestGame(t, ctx, "reap")
+	server := watchServer(ctx)
+	defer server.Close()
+
+	// A watcher that keeps reading answers pings and is kept.
+	reaped := reapedWatchers.Value()
+	alive := dialWatch(t, server, sessions["alice"], game.id, "")
+	go func() {
+		for {
+			if _, _, err := alive.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	// A watcher that never reads never answers pings and is reaped.
+	silent := dialWatch(t, server, sessions["bob"], game.id, "")
+	defer silent.Close()
+
+	waitForWatchers(t, game, 2)
+	waitForWatchers(t, game, 1)
+	time.Sleep(2 * watchIdleTimeout)
+	if reapedWatchers.Value() != reaped+1 {
+		t.Errorf("expected 1 watcher to be reaped, got %d", reapedWatchers.Value()-reaped)
+	}
+	alive.Close()
+	waitForWatchers(t, game, 0)
+}
+
+func TestStreamResumesFromLastEventId(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice", "bob")
+	game := newTestGame(t, ctx, "stream")
+	server := watchServer(ctx)
+	defer server.Close()
+
+	// Last-Event-ID, sent by browsers when they reconnect, wins over since.
+	seq := game.state.Seq
+	r, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/game/"+game.id+"/events?since=0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.AddCookie(&http.Cookie{Name: sessionCookie, Value: sessions["alice"]})
+	r.Header.Set("Last-Event-ID", fmt.Sprint(seq-2))
+	res, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if contentType := res.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("expected an event stream, got '%s'", contentType)
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	for _, expected := range []uint64{seq - 1, seq} {
+		var id string
+		var event Event
+		for scanner.Scan() && scanner.Text() != "" {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "id: ") {
+				id = strings.TrimPrefix(line, "id: ")
+			} else if strings.HasPrefix(line, "data: ") {
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if id != fmt.Sprint(expected) || event.Seq != expected {
+			t.Errorf("expected event %d, got id '%s' and seq %d", expected, id, event.Seq)
+		}
+	}
+}
+
+// listGames gets the games with the status, as the user sees them.
+func listGames(t *testing.T, ctx *Context, session string, status string) []*GameSummary {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/games?status="+status, nil)
+	r.AddCookie(&http.Cookie{Name: sessionCookie, Value: session})
+	w := httptest.NewRecorder()
+	ctx.listGames(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list request failed: %d %s", w.Code, w.Body.String())
+	}
+	var summaries []*GameSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &summaries); err != nil {
+		t.Fatal(err)
+	}
+	return summaries
+}
+
+func TestListGamesFiltersByStatus(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice", "bob")
+	lobby, err := NewGame("lobby", DefaultGameSettings("hk"), ctx.maps["hk"], 1, ctx.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.games[lobby.id] = lobby
+	playing := newTestGame(t, ctx, "playing")
+	finished := newTestGame(t, ctx, "finished")
+	finished.state.Phase = Phase{GameOver: &GameOverPhase{Winner: "bob"}}
+	// Make the order of creation unambiguous.
+	lobby.created = playing.created.Add(time.Minute)
+	finished.created = playing.created.Add(-time.Minute)
+
+	all := listGames(t, ctx, sessions["alice"], "")
+	var ids []string
+	for _, summary := range all {
+		ids = append(ids, summary.Id)
+	}
+	if strings.Join(ids, ",") != "lobby,playing,finished" {
+		t.Errorf("expected the newest games first, got %v", ids)
+	}
+	for status, id := range map[string]string{
+		StatusJoinable:   "lobby",
+		StatusInProgress: "playing",
+		StatusFinished:   "finished",
+	} {
+		summaries := listGames(t, ctx, sessions["alice"], status)
+		if len(summaries) != 1 || summaries[0].Id != id {
+			t.Errorf("expected only '%s' to be %s, got %+v", id, status, summaries)
+		}
+	}
+	if winner := listGames(t, ctx, sessions["alice"], StatusFinished)[0].Winner; winner != "bob" {
+		t.Errorf("expected bob to have won, got '%s'", winner)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/games?status=bogus", nil)
+	r.AddCookie(&http.Cookie{Name: sessionCookie, Value: sessions["alice"]})
+	w := httptest.NewRecorder()
+	ctx.listGames(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown status to be rejected, got %d", w.Code)
+	}
+}
+
+func TestListGamesLeavesOutFullLobbies(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice")
+	settings := DefaultGameSettings("hk")
+	settings.MaxPlayers = 2
+	for _, id := range []string{"open", "full"} {
+		game, err := NewGame(id, settings, ctx.maps["hk"], 1, ctx.store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx.games[id] = game
+	}
+	for _, player := range []string{"alice", "bob"} {
+		if _, err := ctx.games["full"].applyActionLocked(&Action{JoinGame: &JoinGameAction{Player: player}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	joinable := listGames(t, ctx, sessions["alice"], StatusJoinable)
+	if len(joinable) != 1 || joinable[0].Id != "open" {
+		t.Errorf("expected only the open game to be joinable, got %+v", joinable)
+	}
+	full := listGames(t, ctx, sessions["alice"], StatusFull)
+	if len(full) != 1 || full[0].Id != "full" {
+		t.Errorf("expected the full game, got %+v", full)
+	}
+}
+
+func TestAddBotNeedsAdmin(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice", "bob", "carol")
+	game, err := NewGame("bots", DefaultGameSettings("hk"), ctx.maps["hk"], 1, ctx.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.games[game.id] = game
+	for _, player := range []string{"alice", "bob"} {
+		if _, err := game.applyActionLocked(&Action{JoinGame: &JoinGameAction{Player: player}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addBot := func(user string) int {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/game/bots/bots", strings.NewReader(`{ "kind": "random" }`))
+		r.AddCookie(&http.Cookie{Name: sessionCookie, Value: sessions[user]})
+		r = mux.SetURLVars(r, map[string]string{"gameId": game.id})
+		w := httptest.NewRecorder()
+		ctx.addBot(w, r)
+		return w.Code
+	}
+
+	if code := addBot("bob"); code != http.StatusForbidden {
+		t.Errorf("expected bob to be forbidden, got %d", code)
+	}
+	if code := addBot("alice"); code != http.StatusOK {
+		t.Fatalf("expected alice to add a bot, got %d", code)
+	}
+	if player := game.state.findPlayer("random bot 1"); player == nil || player.Bot != RandomBotKind {
+		t.Errorf("expected a random bot to join, got %+v", player)
+	}
+
+	// Users can't join as bots themselves.
+	postAction(ctx, sessions["carol"], game.id, &Action{JoinGame: &JoinGameAction{Bot: RandomBotKind}})
+	if player := game.state.findPlayer("carol"); player != nil {
+		t.Errorf("carol joined as a bot: %+v", player)
+	}
+}
+
+// newFogGame starts a fog of war game between alice and bob, in which the
+// first player deploys to a territory the other can't see. It returns that
+// territory, or nil if the seed deals no such territory.
+func newFogGame(t *testing.T, ctx *Context, id string, seed int64) (*Game, string) {
+	t.Helper()
+	settings := DefaultGameSettings("hk")
+	settings.FogOfWar = true
+	m := ctx.maps["hk"]
+	game, err := NewGame(id, settings, m, seed, ctx.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []*Action{
+		{JoinGame: &JoinGameAction{Player: "alice"}},
+		{JoinGame: &JoinGameAction{Player: "bob"}},
+		{StartGame: &StartGameAction{Player: "alice"}},
+	} {
+		if _, err := game.applyActionLocked(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	active := game.state.ActivePlayer
+	fog := game.state.FogFor(m, otherPlayer(active))
+	for _, territ := range ownedTerrits(&game.state, active) {
+		if !fog.Sees(territ) {
+			if _, err := game.applyActionLocked(&Action{Deploy: &DeployAction{
+				Player:      active,
+				Deployments: map[string]uint64{territ: game.state.Phase.Deploy.Reinforcements},
+			}}); err != nil {
+				t.Fatal(err)
+			}
+			ctx.games[id] = game
+			return game, territ
+		}
+	}
+	return nil, ""
+}
+
+// fetchLog gets the game's log as the user sees it.
+func fetchLog(t *testing.T, ctx *Context, session string, id string) []*LogEntry {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/game/"+id+"/log", nil)
+	r.AddCookie(&http.Cookie{Name: sessionCookie, Value: session})
+	r = mux.SetURLVars(r, map[string]string{"gameId": id})
+	w := httptest.NewRecorder()
+	ctx.getGameLog(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("log request failed: %d %s", w.Code, w.Body.String())
+	}
+	var entries []*LogEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// checkDeployHidden fails if the entry shows the deployment to the territory.
+func checkDeployHidden(t *testing.T, entry *LogEntry, territ string) {
+	t.Helper()
+	if _, found := entry.Action.Deploy.Deployments[territ]; found {
+		t.Errorf("action shows the deployment to '%s'", territ)
+	}
+	for _, event := range entry.Events {
+		if event.Deploy != nil {
+			if _, found := event.Deploy.Deployments[territ]; found {
+				t.Errorf("event shows the deployment to '%s'", territ)
+			}
+		}
+	}
+}
+
+func TestGameLogHidesFoggedDeployments(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice", "bob")
+	var game *Game
+	var hidden string
+	for seed := int64(0); game == nil && seed < 100; seed++ {
+		game, hidden = newFogGame(t, ctx, fmt.Sprintf("fog%d", seed), seed)
+	}
+	if game == nil {
+		t.Fatal("no game with a hidden territory")
+	}
+	active := game.state.ActivePlayer
+
+	entries := fetchLog(t, ctx, sessions[otherPlayer(active)], game.id)
+	checkDeployHidden(t, entries[len(entries)-1], hidden)
+
+	entries = fetchLog(t, ctx, sessions[active], game.id)
+	if _, found := entries[len(entries)-1].Action.Deploy.Deployments[hidden]; !found {
+		t.Errorf("player's own deployment to '%s' is hidden", hidden)
+	}
+}
+
+func TestGameLogKeepsPastFog(t *testing.T) {
+	ctx, sessions := newTestContext(t, "alice", "bob")
+	m := ctx.maps["hk"]
+	// Find a game in which the other player conquers a territory next to the
+	// hidden one on their first turn.
+	for seed := int64(0); seed < 100; seed++ {
+		game, hidden := newFogGame(t, ctx, fmt.Sprintf("fog%d", seed), seed)
+		if game == nil {
+			continue
+		}
+		active := game.state.ActivePlayer
+		other := otherPlayer(active)
+		for _, action := range []*Action{
+			{EndAttack: &EndPhaseAction{Player: active}},
+			{EndReinforce: &EndPhaseAction{Player: active}},
+		} {
+			if _, err := game.applyActionLocked(action); err != nil {
+				t.Fatal(err)
+			}
+		}
+		from, to := "", ""
+		for _, neighbour := range m.Territs[hidden].Neighbours {
+			for _, territ := range enemyNeighbours(&game.state, m, neighbour.Name, active) {
+				if game.state.Owns(active, neighbour.Name) && game.state.Owns(other, territ) {
+					from, to = territ, neighbour.Name
+				}
+			}
+		}
+		if from == "" || game.state.Phase.Deploy == nil {
+			continue
+		}
+		for _, action := range []*Action{
+			{Deploy: &DeployAction{Player: other, Deployments: map[string]uint64{from: game.state.Phase.Deploy.Reinforcements}}},
+			{Blitz: &BlitzAction{AttackAction: AttackAction{Player: other, From: from, To: to}}},
+		} {
+			if _, err := game.applyActionLocked(action); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !game.state.FogFor(m, other).Sees(hidden) {
+			continue
+		}
+
+		entries := fetchLog(t, ctx, sessions[other], game.id)
+		for _, entry := range entries {
+			if entry.Action.Deploy != nil && entry.Player == active {
+				checkDeployHidden(t, entry, hidden)
+			}
+		}
+		return
+	}
+	t.Fatal("no game in which the hidden territory comes into sight")
+}
+
+func TestLoginEscapesRedirect(t *testing.T) {
+	request := httptest.NewRequest("GET", `/login?continue="><script>alert(1)</script>`, nil)
+	recorder := httptest.NewRecorder()
+	getLogin(recorder, request)
+	if body := recorder.Body.String(); strings.Contains(body, "<script>alert") {
+		t.Errorf("redirect was not escaped: %s", body)
+	}
+}
+
+func TestEventStreamDropsStalledWatcher(t *testing.T) {
+	defer func(timeout time.Duration) { watchWriteTimeout = timeout }(watchWriteTimeout)
+	watchWriteTimeout = 100 * time.Millisecond
+	ctx, sessions := newTestContext(t, "alice")
+	game, err := NewGame("stalled", DefaultGameSettings("hk"), ctx.maps["hk"], 1, ctx.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []*Action{
+		{JoinGame: &JoinGameAction{Player: "alice"}},
+		{JoinGame: &JoinGameAction{Player: "bob"}},
+		{StartGame: &StartGameAction{Player: "alice"}},
+	} {
+		if _, err := game.applyActionLocked(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Only the write deadline can end the stream.
+	game.hub.policy = DropEventsForSlowWatchers
+	ctx.games["stalled"] = game
+
+	r := mux.NewRouter()
+	r.HandleFunc("/api/v1/game/{gameId}/events", ctx.streamGameEvents)
+	server := httptest.NewUnstartedServer(r)
+	server.Config.ConnContext = saveConnInContext
+	server.Start()
+	defer server.Close()
+
+	// Connect, then never read.
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.(*net.TCPConn).SetReadBuffer(4096)
+	fmt.Fprintf(conn, "GET /api/v1/game/stalled/events HTTP/1.1\r\nHost: test\r\nCookie: %s=%s\r\n\r\n", sessionCookie, sessions["alice"])
+
+	deadline := time.Now().Add(10 * time.Second)
+	for subscribed := false; time.Now().Before(deadline); {
+		count := game.hub.Stats().Subscribers
+		if count > 0 {
+			subscribed = true
+		} else if subscribed {
+			return
+		}
+		game.lock.Lock()
+		err := game.hub.Publish([]*Event{{Snapshot: &game.state}}, game.fogLocked)
+		game.lock.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Fatal("stalled watcher was never dropped")
+}
+
+func TestLoginOnlyContinuesOnSite(t *testing.T) {
+	tests := map[string]string{
+		"/game/abc":            "/game/abc",
+		"":                     "/",
+		"https://evil.example": "/",
+		"//evil.example":       "/",
+		`/\evil.example`:       "/",
+	}
+	for path, expected := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/login?continue="+url.QueryEscape(path), nil)
+		if actual := continuePath(r); actual != expected {
+			t.Errorf("'%s' continued to '%s'", path, actual)
+		}
+	}
+}
+
+func TestRegisterDoesNotRevealAccounts(t *testing.T) {
+	ctx, _ := newTestContext(t, "alice")
+	taken := postForm(ctx.postRegister, "alice", "not alice's password")
+	missing := postForm(ctx.postLogin, "nobody", "not alice's password")
+	if taken.Code != missing.Code || taken.Body.String() != missing.Body.String() {
+		t.Errorf("registering a taken name failed with %d %s, logging in to a missing account with %d %s",
+			taken.Code, taken.Body.String(), missing.Code, missing.Body.String())
+	}
+	if w := postForm(ctx.postRegister, "alice", "password"); w.Code != http.StatusFound {
+		t.Errorf("registering with alice's password failed: %d", w.Code)
+	}
+}
@@ -1,6 +1,17 @@
 package main
 
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
 type Map struct {
+	Name      string                `json:"name"`
 	AssetPath string                `json:"asset_path"`
 	Territs   map[string]*Territory `json:"territs"`
 	Regions   map[string]*Region    `json:"regions"`
@@ -11,6 +22,9 @@ type Territory struct {
 	Center     string       `json:"center"`
 	Paths      []string     `json:"paths"`
 	Color      string       `json:"color"`
+	// Neutral is the number of neutral troops holding the territory at the
+	// start of a game, or zero if the territory is dealt to a player.
+	Neutral uint64 `json:"neutral,omitempty"`
 }
 
 type Region struct {
@@ -39,8 +53,13 @@ func (m *Map) IsAdjacent(from string, to string) bool {
 
 type Owner interface {
 	Owns(owner string, territ string) bool
+	// Passes returns true if the owner's troops may move through the
+	// territory, e.g. because an ally holds it.
+	Passes(owner string, territ string) bool
 }
 
+// IsConnected returns true if the owner can move troops from one territory to
+// the other through territories they may pass.
 func (m *Map) IsConnected(from string, to string, owner string, ownerChecker Owner) bool {
 	visited := make(map[string]bool)
 	nodes := []string{from}
@@ -49,8 +68,8 @@ func (m *Map) IsConnected(from string, to string, owner string, ownerChecker Own
 		nodes = nodes[:len(nodes)-1]
 		if territ, found := m.Territs[territName]; found {
 			visited[territName] = true
-			if ownerChecker.Owns(owner, territName) {
-				if territName == to {
+			if ownerChecker.Passes(owner, territName) {
+				if territName == to && ownerChecker.Owns(owner, territName) {
 					return true
 				}
 				for idx := range territ.Neighbours {
@@ -66,3 +85,163 @@ func (m *Map) IsConnected(from string, to string, owner string, ownerChecker Own
 	}
 	return false
 }
+
+const mapFileSuffix = ".json"
+
+// LoadMap reads a map from a JSON file.
+func LoadMap(path string) (*Map, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Map
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse '%s': %v", path, err)
+	}
+	if m.Territs == nil {
+		m.Territs = make(map[string]*Territory)
+	}
+	if m.Regions == nil {
+		m.Regions = make(map[string]*Region)
+	}
+	return &m, nil
+}
+
+// LoadMaps reads every map in a directory, keyed by its file name without the
+// extension. Maps that can't be read or fail validation are logged and
+// skipped, so one bad file doesn't keep the others from being served.
+func LoadMaps(dir string) (map[string]*Map, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	maps := make(map[string]*Map)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, mapFileSuffix) {
+			continue
+		}
+		id := strings.TrimSuffix(name, mapFileSuffix)
+		m, err := LoadMap(filepath.Join(dir, name))
+		if err != nil {
+			log.Printf("skipping map '%s': %v", id, err)
+			continue
+		}
+		if err := ValidateMap(m); err != nil {
+			log.Printf("skipping invalid map '%s':\n%v", id, err)
+			continue
+		}
+		if m.Name == "" {
+			m.Name = id
+		}
+		maps[id] = m
+	}
+	return maps, nil
+}
+
+// MapErrors lists every inconsistency found in a map.
+type MapErrors []error
+
+func (errs MapErrors) Error() string {
+	var lines []string
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateMap checks that neighbours are symmetric and refer to existing
+// territories, that regions only contain existing territories, and that every
+// territory can be reached from every other. It returns MapErrors listing all
+// problems, or nil if the map is valid.
+func ValidateMap(m *Map) error {
+	var errs MapErrors
+	if len(m.Territs) == 0 {
+		return MapErrors{fmt.Errorf("map has no territories")}
+	}
+
+	var names []string
+	for name := range m.Territs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		territ := m.Territs[name]
+		if territ == nil {
+			errs = append(errs, fmt.Errorf("territory '%s' is empty", name))
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, neighbour := range territ.Neighbours {
+			if neighbour == nil {
+				errs = append(errs, fmt.Errorf("territory '%s' has an empty neighbour", name))
+				continue
+			}
+			if neighbour.Name == name {
+				errs = append(errs, fmt.Errorf("territory '%s' is its own neighbour", name))
+				continue
+			}
+			if seen[neighbour.Name] {
+				errs = append(errs, fmt.Errorf("territory '%s' lists neighbour '%s' more than once", name, neighbour.Name))
+				continue
+			}
+			seen[neighbour.Name] = true
+			other, found := m.Territs[neighbour.Name]
+			if !found || other == nil {
+				errs = append(errs, fmt.Errorf("territory '%s' has unknown neighbour '%s'", name, neighbour.Name))
+			} else if !m.IsAdjacent(neighbour.Name, name) {
+				errs = append(errs, fmt.Errorf("territory '%s' borders '%s', but not the other way around", name, neighbour.Name))
+			}
+		}
+	}
+
+	var regionNames []string
+	for name := range m.Regions {
+		regionNames = append(regionNames, name)
+	}
+	sort.Strings(regionNames)
+	for _, name := range regionNames {
+		region := m.Regions[name]
+		if region == nil || len(region.Territs) == 0 {
+			errs = append(errs, fmt.Errorf("region '%s' has no territories", name))
+			continue
+		}
+		for _, territ := range region.Territs {
+			if _, found := m.Territs[territ]; !found {
+				errs = append(errs, fmt.Errorf("region '%s' has unknown territory '%s'", name, territ))
+			}
+		}
+	}
+
+	// Every territory must be reachable from the first one.
+	visited := map[string]bool{names[0]: true}
+	nodes := []string{names[0]}
+	for len(nodes) > 0 {
+		territName := nodes[len(nodes)-1]
+		nodes = nodes[:len(nodes)-1]
+		territ := m.Territs[territName]
+		if territ == nil {
+			continue
+		}
+		for _, neighbour := range territ.Neighbours {
+			if neighbour == nil {
+				continue
+			}
+			if _, found := m.Territs[neighbour.Name]; found && !visited[neighbour.Name] {
+				visited[neighbour.Name] = true
+				nodes = append(nodes, neighbour.Name)
+			}
+		}
+	}
+	for _, name := range names {
+		if !visited[name] {
+			errs = append(errs, fmt.Errorf("territory '%s' is not reachable from '%s'", name, names[0]))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
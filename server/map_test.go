@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMaps(t *testing.T) {
+	dir := t.TempDir()
+	data, err := ioutil.ReadFile("../maps/hk.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"hk.json":        string(data),
+		"unnamed.json":   `{ "territs": { "a": {} } }`,
+		"invalid.json":   `{ "territs": { "a": { "neighbours": [{ "name": "b" }] } } }`,
+		"malformed.json": `{ "territs": `,
+		"notes.txt":      "not a map",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	maps, err := LoadMaps(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The invalid and malformed maps are skipped.
+	if len(maps) != 2 {
+		t.Fatalf("expected 2 maps, got %v", maps)
+	}
+	if name := maps["hk"].Name; name != "Hong Kong" {
+		t.Errorf("expected the map's own name, got '%s'", name)
+	}
+	// Maps without a name are called after their file.
+	if name := maps["unnamed"].Name; name != "unnamed" {
+		t.Errorf("expected the file name, got '%s'", name)
+	}
+}
+
+// newValidationMap builds a map from the neighbours of each territory and the
+// territories of each region.
+func newValidationMap(neighbours map[string][]string, regions map[string][]string) *Map {
+	m := &Map{Territs: make(map[string]*Territory), Regions: make(map[string]*Region)}
+	for name, names := range neighbours {
+		territ := &Territory{}
+		for _, neighbour := range names {
+			territ.Neighbours = append(territ.Neighbours, &Neighbour{Name: neighbour})
+		}
+		m.Territs[name] = territ
+	}
+	for name, territs := range regions {
+		m.Regions[name] = &Region{Territs: territs}
+	}
+	return m
+}
+
+func TestValidateMap(t *testing.T) {
+	tests := []struct {
+		name       string
+		neighbours map[string][]string
+		regions    map[string][]string
+		errors     int
+	}{
+		{"valid", map[string][]string{"a": {"b"}, "b": {"a", "c"}, "c": {"b"}}, map[string][]string{"r": {"a", "b"}}, 0},
+		{"no territories", map[string][]string{}, nil, 1},
+		{"own neighbour", map[string][]string{"a": {"a", "b"}, "b": {"a"}}, nil, 1},
+		{"repeated neighbour", map[string][]string{"a": {"b", "b"}, "b": {"a"}}, nil, 1},
+		{"unknown neighbour", map[string][]string{"a": {"b", "z"}, "b": {"a"}}, nil, 1},
+		{"one way", map[string][]string{"a": {"b"}, "b": {"a", "c"}, "c": {}}, nil, 1},
+		{"unreachable", map[string][]string{"a": {"b"}, "b": {"a"}, "c": {"d"}, "d": {"c"}}, nil, 2},
+		{"empty region", map[string][]string{"a": {"b"}, "b": {"a"}}, map[string][]string{"r": {}}, 1},
+		{"unknown region territory", map[string][]string{"a": {"b"}, "b": {"a"}}, map[string][]string{"r": {"a", "z"}}, 1},
+	}
+	for _, test := range tests {
+		err := ValidateMap(newValidationMap(test.neighbours, test.regions))
+		if test.errors == 0 {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+			continue
+		}
+		errs, ok := err.(MapErrors)
+		if !ok || len(errs) != test.errors {
+			t.Errorf("%s: expected %d errors, got %v", test.name, test.errors, err)
+		}
+	}
+
+	if err := ValidateMap(loadTestMap(t)); err != nil {
+		t.Errorf("Hong Kong: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+)
+
+// SpoilsMode decides how much a set of spoils is worth.
+type SpoilsMode string
+
+const (
+	// FixedSpoils pays a bonus that depends only on the colors in the set.
+	FixedSpoils SpoilsMode = "fixed"
+	// EscalatingSpoils pays more for every set cashed in the game, whatever
+	// its colors.
+	EscalatingSpoils SpoilsMode = "escalating"
+	// CappedSpoils escalates like EscalatingSpoils but never pays more than
+	// the cap.
+	CappedSpoils SpoilsMode = "capped"
+)
+
+// escalatingBonuses are the values of the first sets cashed in an escalating
+// game. Each set after these is worth escalatingStep more than the last.
+var escalatingBonuses = []uint64{4, 6, 8, 10, 12, 15}
+
+const escalatingStep = 5
+
+// SpoilsSchedule is the number of troops a set of spoils is worth.
+type SpoilsSchedule struct {
+	// Mode is empty in settings saved before spoils had modes, which means
+	// FixedSpoils.
+	Mode SpoilsMode `json:"mode,omitempty"`
+	// Red, Green, Blue and Mixed are only used by FixedSpoils.
+	Red   uint64 `json:"red"`
+	Green uint64 `json:"green"`
+	Blue  uint64 `json:"blue"`
+	Mixed uint64 `json:"mixed"`
+	// Cap is only used by CappedSpoils.
+	Cap uint64 `json:"cap,omitempty"`
+	// Territory is the number of troops placed on each cashed spoil's
+	// territory, if the player owns it.
+	Territory uint64 `json:"territory"`
+}
+
+func ParseSpoilsMode(mode string) (SpoilsMode, error) {
+	switch SpoilsMode(mode) {
+	case FixedSpoils, EscalatingSpoils, CappedSpoils:
+		return SpoilsMode(mode), nil
+	}
+	return "", fmt.Errorf("unknown spoils mode '%s'", mode)
+}
+
+// Bonus returns the troops paid for cashing a set with the given colors, when
+// setsCashed sets have already been cashed in the game.
+func (s *SpoilsSchedule) Bonus(red int, green int, blue int, setsCashed uint64) (uint64, error) {
+	if s.Mode == EscalatingSpoils || s.Mode == CappedSpoils {
+		if red != 3 && green != 3 && blue != 3 && !(red == 1 && green == 1 && blue == 1) {
+			return 0, fmt.Errorf("invalid spoils")
+		}
+		return s.NextBonus(setsCashed), nil
+	}
+	if red == 3 {
+		return s.Red, nil
+	} else if green == 3 {
+		return s.Green, nil
+	} else if blue == 3 {
+		return s.Blue, nil
+	} else if red == 1 && green == 1 && blue == 1 {
+		return s.Mixed, nil
+	}
+	return 0, fmt.Errorf("invalid spoils")
+}
+
+// NextBonus returns the value of the next set to be cashed, or zero if the
+// value depends on the set's colors.
+func (s *SpoilsSchedule) NextBonus(setsCashed uint64) uint64 {
+	if s.Mode != EscalatingSpoils && s.Mode != CappedSpoils {
+		return 0
+	}
+	var bonus uint64
+	if setsCashed < uint64(len(escalatingBonuses)) {
+		bonus = escalatingBonuses[setsCashed]
+	} else {
+		last := escalatingBonuses[len(escalatingBonuses)-1]
+		bonus = last + escalatingStep*(setsCashed-uint64(len(escalatingBonuses))+1)
+	}
+	if s.Mode == CappedSpoils && bonus > s.Cap {
+		bonus = s.Cap
+	}
+	return bonus
+}
+
+// GameSettings are the rules of a game, chosen when it is created.
+type GameSettings struct {
+	Map            string `json:"map"`
+	MaxPlayers     int    `json:"max_players"`
+	StartingTroops uint64 `json:"starting_troops"`
+	// Players receive Territories / ReinforcementDivisor troops per turn, but
+	// never fewer than MinReinforcements, plus region bonuses.
+	ReinforcementDivisor uint64         `json:"reinforcement_divisor"`
+	MinReinforcements    uint64         `json:"min_reinforcements"`
+	Spoils               SpoilsSchedule `json:"spoils"`
+	// HandLimit is the number of spoils at which a player must cash in.
+	HandLimit int `json:"hand_limit"`
+	// TurnSeconds limits how long a turn may take, or is zero for no limit.
+	// Once it runs out, players use up their time bank, and then the server
+	// plays the rest of the turn for them.
+	TurnSeconds     uint64 `json:"turn_seconds,omitempty"`
+	TimeBankSeconds uint64 `json:"time_bank_seconds,omitempty"`
+	// AbandonAfter is the number of turns in a row a player may time out
+	// before they are marked abandoned, or zero to never abandon players.
+	AbandonAfter int `json:"abandon_after,omitempty"`
+	// FogOfWar hides the troops in territories that players neither own nor
+	// border.
+	FogOfWar bool `json:"fog_of_war,omitempty"`
+	// NeutralPlayer deals a share of the territories to a neutral owner as if
+	// it were another player. Neutral territories start with NeutralTroops
+	// troops each, or StartingTroops if NeutralTroops is zero. Maps may also
+	// hold territories for the neutral owner, whatever the settings.
+	NeutralPlayer bool   `json:"neutral_player,omitempty"`
+	NeutralTroops uint64 `json:"neutral_troops,omitempty"`
+	// Draft is empty in settings saved before drafts, which means
+	// RandomDraft.
+	Draft DraftMode `json:"draft,omitempty"`
+	// Teams lets players choose a team in the lobby. A team wins together
+	// once every other player is eliminated.
+	Teams bool `json:"teams,omitempty"`
+	// NoFriendlyFire stops players attacking their teammates.
+	NoFriendlyFire bool `json:"no_friendly_fire,omitempty"`
+	// AlliedReinforce lets players reinforce through their teammates'
+	// territories.
+	AlliedReinforce bool `json:"allied_reinforce,omitempty"`
+}
+
+var playerColors = []string{"red", "blue", "green", "yellow", "brown", "teal"}
+
+func DefaultGameSettings(mapId string) GameSettings {
+	return GameSettings{
+		Map:                  mapId,
+		MaxPlayers:           len(playerColors),
+		StartingTroops:       3,
+		ReinforcementDivisor: 3,
+		MinReinforcements:    3,
+		Spoils: SpoilsSchedule{
+			Mode:      FixedSpoils,
+			Red:       4,
+			Green:     6,
+			Blue:      8,
+			Mixed:     10,
+			Territory: 2,
+		},
+		HandLimit: 5,
+	}
+}
+
+func (s *GameSettings) Validate() error {
+	if s.Map == "" {
+		return fmt.Errorf("no map chosen")
+	}
+	if s.MaxPlayers < 2 || s.MaxPlayers > len(playerColors) {
+		return fmt.Errorf("max players must be between 2 and %d", len(playerColors))
+	}
+	if s.StartingTroops == 0 {
+		return fmt.Errorf("starting troops must be at least 1")
+	}
+	if s.ReinforcementDivisor == 0 {
+		return fmt.Errorf("reinforcement divisor must be at least 1")
+	}
+	if s.Spoils.Mode == CappedSpoils && s.Spoils.Cap == 0 {
+		return fmt.Errorf("capped spoils need a cap")
+	}
+	// A set worth nothing could leave a player with nothing to deploy, and
+	// deploying nothing isn't allowed.
+	if (s.Spoils.Mode == FixedSpoils || s.Spoils.Mode == "") &&
+		(s.Spoils.Red == 0 || s.Spoils.Green == 0 || s.Spoils.Blue == 0 || s.Spoils.Mixed == 0) {
+		return fmt.Errorf("spoils must be worth at least 1 troop")
+	}
+	if s.MinReinforcements == 0 {
+		return fmt.Errorf("minimum reinforcements must be at least 1")
+	}
+	// Any 5 spoils hold a set, so a lower limit could force a player to cash
+	// in a set they don't have.
+	if s.HandLimit < 5 {
+		return fmt.Errorf("hand limit must be at least 5")
+	}
+	if s.TurnSeconds == 0 && (s.TimeBankSeconds != 0 || s.AbandonAfter != 0) {
+		return fmt.Errorf("time banks and abandoning need a turn time limit")
+	}
+	if s.AbandonAfter < 0 {
+		return fmt.Errorf("abandon after must not be negative")
+	}
+	if !s.NeutralPlayer && s.NeutralTroops != 0 {
+		return fmt.Errorf("neutral troops need a neutral player")
+	}
+	if !s.Teams && (s.NoFriendlyFire || s.AlliedReinforce) {
+		return fmt.Errorf("team rules need teams")
+	}
+	return nil
+}
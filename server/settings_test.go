@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *GameSettings)
+		valid  bool
+	}{
+		{"defaults", func(s *GameSettings) {}, true},
+		{"no map", func(s *GameSettings) { s.Map = "" }, false},
+		{"one player", func(s *GameSettings) { s.MaxPlayers = 1 }, false},
+		{"more players than colors", func(s *GameSettings) { s.MaxPlayers = len(playerColors) + 1 }, false},
+		{"no starting troops", func(s *GameSettings) { s.StartingTroops = 0 }, false},
+		{"no reinforcement divisor", func(s *GameSettings) { s.ReinforcementDivisor = 0 }, false},
+		{"small hand limit", func(s *GameSettings) { s.HandLimit = 2 }, false},
+		{"hand limit without a guaranteed set", func(s *GameSettings) { s.HandLimit = 4 }, false},
+		{"no minimum reinforcements", func(s *GameSettings) { s.MinReinforcements = 0 }, false},
+		{"capped without cap", func(s *GameSettings) { s.Spoils.Mode = CappedSpoils }, false},
+		{"worthless fixed spoils", func(s *GameSettings) { s.Spoils.Mixed = 0 }, false},
+		{"escalating ignores fixed values", func(s *GameSettings) {
+			s.Spoils.Mode = EscalatingSpoils
+			s.Spoils.Red = 0
+		}, true},
+		{"time bank without limit", func(s *GameSettings) { s.TimeBankSeconds = 10 }, false},
+		{"neutral troops without neutral player", func(s *GameSettings) { s.NeutralTroops = 2 }, false},
+		{"team rules without teams", func(s *GameSettings) { s.NoFriendlyFire = true }, false},
+	}
+	for _, test := range tests {
+		settings := DefaultGameSettings("hk")
+		test.change(&settings)
+		err := settings.Validate()
+		if test.valid && err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: invalid settings accepted", test.name)
+		}
+	}
+}
+
+func TestSpoilsBonus(t *testing.T) {
+	fixed := DefaultGameSettings("hk").Spoils
+	escalating := SpoilsSchedule{Mode: EscalatingSpoils}
+	capped := SpoilsSchedule{Mode: CappedSpoils, Cap: 10}
+	tests := []struct {
+		name       string
+		schedule   SpoilsSchedule
+		colors     [3]int
+		setsCashed uint64
+		bonus      uint64
+	}{
+		{"fixed red", fixed, [3]int{3, 0, 0}, 7, fixed.Red},
+		{"fixed mixed", fixed, [3]int{1, 1, 1}, 0, fixed.Mixed},
+		{"escalating first", escalating, [3]int{0, 3, 0}, 0, 4},
+		{"escalating mixed", escalating, [3]int{1, 1, 1}, 5, 15},
+		{"escalating past table", escalating, [3]int{0, 0, 3}, 7, 25},
+		{"capped below cap", capped, [3]int{3, 0, 0}, 2, 8},
+		{"capped at cap", capped, [3]int{3, 0, 0}, 9, 10},
+	}
+	for _, test := range tests {
+		bonus, err := test.schedule.Bonus(test.colors[0], test.colors[1], test.colors[2], test.setsCashed)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if bonus != test.bonus {
+			t.Errorf("%s: expected bonus %d, got %d", test.name, test.bonus, bonus)
+		}
+	}
+	if _, err := escalating.Bonus(2, 1, 0, 0); err == nil {
+		t.Error("invalid set was accepted")
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GameRecord is the persisted form of a game. GameState hides the spoil pool
+// from clients, so it is stored alongside the state explicitly.
+type GameRecord struct {
+	MapId     string    `json:"map_id"`
+	State     GameState `json:"state"`
+	SpoilPool []*Spoil  `json:"spoil_pool"`
+	Seed      int64     `json:"seed"`
+	Actions   uint64    `json:"actions"`
+	Created   time.Time `json:"created"`
+}
+
+func NewGameRecord(state *GameState) *GameRecord {
+	return &GameRecord{
+		MapId:     state.Map,
+		State:     *state,
+		SpoilPool: state.spoilPool,
+		Seed:      state.seed,
+		Actions:   state.actions,
+	}
+}
+
+func (record *GameRecord) GameState() GameState {
+	state := record.State
+	state.spoilPool = record.SpoilPool
+	state.seed = record.Seed
+	state.actions = record.Actions
+	state.randomSource = NewSeededRandom
+	if state.Settings.Map == "" {
+		state.Settings = DefaultGameSettings(record.MapId)
+	}
+	if state.Territs == nil {
+		state.Territs = make(map[string]*TerritoryMut)
+	}
+	return state
+}
+
+// clone returns a deep copy of the state that applies actions with the same
+// randomness and at the same time as the original.
+func (g *GameState) clone() (*GameState, error) {
+	data, err := json.Marshal(NewGameRecord(g))
+	if err != nil {
+		return nil, err
+	}
+	var record GameRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	state := record.GameState()
+	state.randomSource = g.randomSource
+	state.rng = g.rng
+	state.now = g.now
+	state.timingOut = g.timingOut
+	return &state, nil
+}
+
+type Store interface {
+	SaveGame(id string, record *GameRecord) error
+	LoadGames() (map[string]*GameRecord, error)
+
+	// CreateLog starts a new append-only log for a game.
+	CreateLog(id string, header *LogHeader) error
+	AppendLog(id string, entry *LogEntry) error
+	// LoadLog returns ErrNoLog if the game was never logged.
+	LoadLog(id string) (*GameLog, error)
+
+	SaveAccounts(record *AccountsRecord) error
+	LoadAccounts() (*AccountsRecord, error)
+}
+
+// NullStore discards everything. Used when persistence is disabled.
+type NullStore struct{}
+
+func (NullStore) SaveGame(id string, record *GameRecord) error {
+	return nil
+}
+
+func (NullStore) LoadGames() (map[string]*GameRecord, error) {
+	return map[string]*GameRecord{}, nil
+}
+
+func (NullStore) CreateLog(id string, header *LogHeader) error {
+	return nil
+}
+
+func (NullStore) AppendLog(id string, entry *LogEntry) error {
+	return nil
+}
+
+func (NullStore) LoadLog(id string) (*GameLog, error) {
+	return nil, ErrNoLog
+}
+
+func (NullStore) SaveAccounts(record *AccountsRecord) error {
+	return nil
+}
+
+func (NullStore) LoadAccounts() (*AccountsRecord, error) {
+	return &AccountsRecord{}, nil
+}
+
+// FileStore keeps one JSON file per game in a directory.
+type FileStore struct {
+	dir string
+}
+
+const gameFileSuffix = ".game.json"
+const logFileSuffix = ".log.jsonl"
+const accountsFile = "accounts.json"
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) gamePath(id string) string {
+	return filepath.Join(s.dir, id+gameFileSuffix)
+}
+
+func (s *FileStore) logPath(id string) string {
+	return filepath.Join(s.dir, id+logFileSuffix)
+}
+
+func (s *FileStore) SaveGame(id string, record *GameRecord) error {
+	return s.writeJSON(s.gamePath(id), record)
+}
+
+// writeJSON writes to a temporary file and renames it over the old one so that
+// a crash mid-write never leaves a truncated file behind.
+func (s *FileStore) writeJSON(path string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(s.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FileStore) LoadGames() (map[string]*GameRecord, error) {
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]*GameRecord)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, gameFileSuffix) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var record GameRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to parse '%s': %v", name, err)
+		}
+		records[strings.TrimSuffix(name, gameFileSuffix)] = &record
+	}
+	return records, nil
+}
+
+func (s *FileStore) appendLine(id string, flags int, line *LogLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	f, err := os.OpenFile(s.logPath(id), os.O_WRONLY|os.O_APPEND|flags, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *FileStore) CreateLog(id string, header *LogHeader) error {
+	return s.appendLine(id, os.O_CREATE|os.O_EXCL, &LogLine{Header: header})
+}
+
+func (s *FileStore) AppendLog(id string, entry *LogEntry) error {
+	return s.appendLine(id, 0, &LogLine{Entry: entry})
+}
+
+func (s *FileStore) LoadLog(id string) (*GameLog, error) {
+	f, err := os.Open(s.logPath(id))
+	if os.IsNotExist(err) {
+		return nil, ErrNoLog
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var gameLog *GameLog
+	decoder := json.NewDecoder(f)
+	for decoder.More() {
+		var line LogLine
+		if err := decoder.Decode(&line); err != nil {
+			return nil, fmt.Errorf("failed to parse log for game '%s': %v", id, err)
+		}
+		if line.Header != nil {
+			gameLog = &GameLog{Header: *line.Header}
+		} else if line.Entry != nil {
+			if gameLog == nil {
+				return nil, fmt.Errorf("log for game '%s' has no header", id)
+			}
+			gameLog.Entries = append(gameLog.Entries, line.Entry)
+		}
+	}
+	if gameLog == nil {
+		return nil, ErrNoLog
+	}
+	return gameLog, nil
+}
+
+func (s *FileStore) SaveAccounts(record *AccountsRecord) error {
+	return s.writeJSON(filepath.Join(s.dir, accountsFile), record)
+}
+
+func (s *FileStore) LoadAccounts() (*AccountsRecord, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, accountsFile))
+	if os.IsNotExist(err) {
+		return &AccountsRecord{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var record AccountsRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse '%s': %v", accountsFile, err)
+	}
+	return &record, nil
+}
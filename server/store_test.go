@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, err := NewTestGameHongKong(loadTestMap(t), NullStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(game.state.spoilPool) == 0 {
+		t.Fatal("expected spoils in the pool")
+	}
+	if err := store.SaveGame(game.id, NewGameRecord(&game.state)); err != nil {
+		t.Fatal(err)
+	}
+	// Saving again replaces the game rather than adding another.
+	if err := store.SaveGame(game.id, NewGameRecord(&game.state)); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.LoadGames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 game, got %d", len(records))
+	}
+	record, found := records[game.id]
+	if !found {
+		t.Fatalf("game '%s' was not loaded", game.id)
+	}
+	state := record.GameState()
+	saved, err := json.Marshal(game.state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(saved) != string(loaded) {
+		t.Errorf("loaded game differs:\n%s\n%s", saved, loaded)
+	}
+	if !reflect.DeepEqual(state.spoilPool, game.state.spoilPool) {
+		t.Error("spoil pool was not restored")
+	}
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type point struct {
+	x float64
+	y float64
+}
+
+// polygon is a closed outline approximating one subpath of an SVG path.
+type polygon []point
+
+type bounds struct {
+	min point
+	max point
+}
+
+func (b bounds) expand(d float64) bounds {
+	return bounds{point{b.min.x - d, b.min.y - d}, point{b.max.x + d, b.max.y + d}}
+}
+
+func (b bounds) intersects(o bounds) bool {
+	return b.min.x <= o.max.x && o.min.x <= b.max.x && b.min.y <= o.max.y && o.min.y <= b.max.y
+}
+
+func polygonsBounds(polygons []polygon) bounds {
+	b := bounds{point{math.Inf(1), math.Inf(1)}, point{math.Inf(-1), math.Inf(-1)}}
+	for _, poly := range polygons {
+		for _, p := range poly {
+			b.min.x = math.Min(b.min.x, p.x)
+			b.min.y = math.Min(b.min.y, p.y)
+			b.max.x = math.Max(b.max.x, p.x)
+			b.max.y = math.Max(b.max.y, p.y)
+		}
+	}
+	return b
+}
+
+// areaCentroid returns the signed area and centroid of the polygon.
+func (poly polygon) areaCentroid() (float64, point) {
+	var area, cx, cy float64
+	for i := range poly {
+		a := poly[i]
+		b := poly[(i+1)%len(poly)]
+		cross := a.x*b.y - b.x*a.y
+		area += cross
+		cx += (a.x + b.x) * cross
+		cy += (a.y + b.y) * cross
+	}
+	area /= 2
+	if area == 0 {
+		return 0, point{}
+	}
+	return area, point{cx / (6 * area), cy / (6 * area)}
+}
+
+// territoryCenter returns the centroid of the largest outline, which keeps the
+// troop marker off small outlying islands. Degenerate outlines fall back to the
+// center of the bounding box.
+func territoryCenter(polygons []polygon) point {
+	var best point
+	bestArea := 0.0
+	for _, poly := range polygons {
+		area, centroid := poly.areaCentroid()
+		if math.Abs(area) > bestArea {
+			bestArea = math.Abs(area)
+			best = centroid
+		}
+	}
+	if bestArea == 0 {
+		b := polygonsBounds(polygons)
+		return point{(b.min.x + b.max.x) / 2, (b.min.y + b.max.y) / 2}
+	}
+	return best
+}
+
+func distanceToSegment(p point, a point, b point) float64 {
+	dx := b.x - a.x
+	dy := b.y - a.y
+	lengthSq := dx*dx + dy*dy
+	t := 0.0
+	if lengthSq > 0 {
+		t = math.Max(0, math.Min(1, ((p.x-a.x)*dx+(p.y-a.y)*dy)/lengthSq))
+	}
+	return math.Hypot(p.x-(a.x+t*dx), p.y-(a.y+t*dy))
+}
+
+// outlinesTouch returns true if any vertex of one set of outlines lies within
+// the tolerance of an edge of the other.
+func outlinesTouch(from []polygon, to []polygon, tolerance float64) bool {
+	for _, fromPoly := range from {
+		for _, p := range fromPoly {
+			for _, toPoly := range to {
+				for i := range toPoly {
+					if distanceToSegment(p, toPoly[i], toPoly[(i+1)%len(toPoly)]) <= tolerance {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+var pathTokenPattern = regexp.MustCompile(`[MmLlHhVvCcSsQqTtAaZz]|[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+
+// bezierSteps is the number of line segments used to approximate a curve.
+const bezierSteps = 8
+
+// parsePath approximates the SVG path data as polygons, one per subpath.
+// Curves are flattened into line segments and arcs are replaced by a straight
+// line to their end point.
+func parsePath(d string) ([]polygon, error) {
+	tokens := pathTokenPattern.FindAllString(d, -1)
+	var polygons []polygon
+	var current polygon
+	var pos, start, lastControl point
+	var command byte
+	idx := 0
+
+	isCommand := func(token string) bool {
+		return len(token) == 1 && strings.ContainsAny(token, "MmLlHhVvCcSsQqTtAaZz")
+	}
+	next := func() (float64, error) {
+		if idx >= len(tokens) || isCommand(tokens[idx]) {
+			return 0, fmt.Errorf("path ended early after command '%c'", command)
+		}
+		value, err := strconv.ParseFloat(tokens[idx], 64)
+		idx += 1
+		return value, err
+	}
+	nextPoint := func(relative bool) (point, error) {
+		x, err := next()
+		if err != nil {
+			return point{}, err
+		}
+		y, err := next()
+		if err != nil {
+			return point{}, err
+		}
+		if relative {
+			return point{pos.x + x, pos.y + y}, nil
+		}
+		return point{x, y}, nil
+	}
+	flush := func() {
+		if len(current) > 1 {
+			polygons = append(polygons, current)
+		}
+		current = nil
+	}
+	cubic := func(p0 point, p1 point, p2 point, p3 point) {
+		for step := 1; step <= bezierSteps; step++ {
+			t := float64(step) / bezierSteps
+			u := 1 - t
+			current = append(current, point{
+				u*u*u*p0.x + 3*u*u*t*p1.x + 3*u*t*t*p2.x + t*t*t*p3.x,
+				u*u*u*p0.y + 3*u*u*t*p1.y + 3*u*t*t*p2.y + t*t*t*p3.y,
+			})
+		}
+	}
+	quadratic := func(p0 point, p1 point, p2 point) {
+		for step := 1; step <= bezierSteps; step++ {
+			t := float64(step) / bezierSteps
+			u := 1 - t
+			current = append(current, point{
+				u*u*p0.x + 2*u*t*p1.x + t*t*p2.x,
+				u*u*p0.y + 2*u*t*p1.y + t*t*p2.y,
+			})
+		}
+	}
+
+	for idx < len(tokens) {
+		if isCommand(tokens[idx]) {
+			command = tokens[idx][0]
+			idx += 1
+		} else if command == 0 {
+			return nil, fmt.Errorf("path does not start with a command")
+		}
+		relative := command >= 'a' && command <= 'z'
+		switch command {
+		case 'M', 'm':
+			p, err := nextPoint(relative)
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			pos, start, lastControl = p, p, p
+			current = polygon{p}
+			// Further coordinate pairs are implicit line-tos.
+			if relative {
+				command = 'l'
+			} else {
+				command = 'L'
+			}
+		case 'L', 'l', 'T', 't':
+			p, err := nextPoint(relative)
+			if err != nil {
+				return nil, err
+			}
+			if command == 'T' || command == 't' {
+				control := point{2*pos.x - lastControl.x, 2*pos.y - lastControl.y}
+				quadratic(pos, control, p)
+				lastControl = control
+			} else {
+				current = append(current, p)
+				lastControl = p
+			}
+			pos = p
+		case 'H', 'h', 'V', 'v':
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			p := pos
+			switch command {
+			case 'H':
+				p.x = value
+			case 'h':
+				p.x += value
+			case 'V':
+				p.y = value
+			case 'v':
+				p.y += value
+			}
+			current = append(current, p)
+			pos, lastControl = p, p
+		case 'C', 'c', 'S', 's':
+			var c1 point
+			if command == 'S' || command == 's' {
+				c1 = point{2*pos.x - lastControl.x, 2*pos.y - lastControl.y}
+			} else {
+				p, err := nextPoint(relative)
+				if err != nil {
+					return nil, err
+				}
+				c1 = p
+			}
+			c2, err := nextPoint(relative)
+			if err != nil {
+				return nil, err
+			}
+			p, err := nextPoint(relative)
+			if err != nil {
+				return nil, err
+			}
+			cubic(pos, c1, c2, p)
+			pos, lastControl = p, c2
+		case 'Q', 'q':
+			c, err := nextPoint(relative)
+			if err != nil {
+				return nil, err
+			}
+			p, err := nextPoint(relative)
+			if err != nil {
+				return nil, err
+			}
+			quadratic(pos, c, p)
+			pos, lastControl = p, c
+		case 'A', 'a':
+			// Radii, rotation and flags are skipped.
+			for i := 0; i < 5; i++ {
+				if _, err := next(); err != nil {
+					return nil, err
+				}
+			}
+			p, err := nextPoint(relative)
+			if err != nil {
+				return nil, err
+			}
+			current = append(current, p)
+			pos, lastControl = p, p
+		case 'Z', 'z':
+			if idx < len(tokens) && !isCommand(tokens[idx]) {
+				return nil, fmt.Errorf("unexpected '%s' after command '%c'", tokens[idx], command)
+			}
+			flush()
+			pos, lastControl = start, start
+			current = polygon{start}
+		}
+	}
+	flush()
+	return polygons, nil
+}
+
+// svgElement captures the attributes we care about from any SVG element.
+type svgElement struct {
+	XMLName  xml.Name
+	Id       string       `xml:"id,attr"`
+	D        string       `xml:"d,attr"`
+	Points   string       `xml:"points,attr"`
+	Fill     string       `xml:"fill,attr"`
+	Children []svgElement `xml:",any"`
+}
+
+// pathData returns the element's outline as path data. Polygons and
+// polylines are converted to an equivalent path.
+func (e *svgElement) pathData() string {
+	switch e.XMLName.Local {
+	case "path":
+		return strings.Join(strings.Fields(e.D), " ")
+	case "polygon", "polyline":
+		coords := strings.FieldsFunc(e.Points, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+		})
+		if len(coords) < 4 {
+			return ""
+		}
+		var b strings.Builder
+		for i := 0; i+1 < len(coords); i += 2 {
+			if i == 0 {
+				b.WriteString("M")
+			} else {
+				b.WriteString("L")
+			}
+			b.WriteString(coords[i] + "," + coords[i+1])
+		}
+		b.WriteString("z")
+		return b.String()
+	}
+	return ""
+}
+
+// collectPaths returns the path data of the element and all its descendants.
+func (e *svgElement) collectPaths() []string {
+	var paths []string
+	if d := e.pathData(); d != "" {
+		paths = append(paths, d)
+	}
+	for idx := range e.Children {
+		paths = append(paths, e.Children[idx].collectPaths()...)
+	}
+	return paths
+}
+
+// collectTerritories finds every path, polygon or group with an id. Groups
+// without an id are searched for territories.
+func (e *svgElement) collectTerritories() []*svgElement {
+	var territs []*svgElement
+	for idx := range e.Children {
+		child := &e.Children[idx]
+		switch child.XMLName.Local {
+		case "path", "polygon", "polyline":
+			if child.Id != "" {
+				territs = append(territs, child)
+			}
+		case "g":
+			if child.Id != "" {
+				territs = append(territs, child)
+			} else {
+				territs = append(territs, child.collectTerritories()...)
+			}
+		}
+	}
+	return territs
+}
+
+var illustratorEscapePattern = regexp.MustCompile(`_x([0-9A-Fa-f]{2})_`)
+
+// territoryName undoes the escaping that Illustrator applies to layer names.
+func territoryName(id string) string {
+	name := strings.TrimSuffix(id, "_1_")
+	name = illustratorEscapePattern.ReplaceAllStringFunc(name, func(escape string) string {
+		code, _ := strconv.ParseUint(escape[2:4], 16, 8)
+		return string(rune(code))
+	})
+	return strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+}
+
+// ImportSVG builds a map from an SVG with one path or group per territory.
+// Territories whose outlines come within the tolerance of each other become
+// neighbours. Transforms are ignored and regions are left for the map author.
+func ImportSVG(r io.Reader, tolerance float64) (*Map, error) {
+	var root svgElement
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "svg" {
+		return nil, fmt.Errorf("not an SVG document")
+	}
+
+	m := &Map{
+		Territs: make(map[string]*Territory),
+		Regions: make(map[string]*Region),
+	}
+	outlines := make(map[string][]polygon)
+	for _, element := range root.collectTerritories() {
+		name := territoryName(element.Id)
+		if _, found := m.Territs[name]; found {
+			return nil, fmt.Errorf("territory '%s' appears more than once", name)
+		}
+		paths := element.collectPaths()
+		var polygons []polygon
+		for _, d := range paths {
+			p, err := parsePath(d)
+			if err != nil {
+				return nil, fmt.Errorf("territory '%s': %v", name, err)
+			}
+			polygons = append(polygons, p...)
+		}
+		if len(polygons) == 0 {
+			return nil, fmt.Errorf("territory '%s' has no outline", name)
+		}
+		center := territoryCenter(polygons)
+		m.Territs[name] = &Territory{
+			Neighbours: []*Neighbour{},
+			Center:     fmt.Sprintf("%.0f %.0f", center.x, center.y),
+			Paths:      paths,
+			Color:      element.Fill,
+		}
+		outlines[name] = polygons
+	}
+
+	var names []string
+	for name := range outlines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, from := range names {
+		fromBounds := polygonsBounds(outlines[from]).expand(tolerance)
+		for _, to := range names[i+1:] {
+			if !fromBounds.intersects(polygonsBounds(outlines[to])) {
+				continue
+			}
+			if outlinesTouch(outlines[from], outlines[to], tolerance) || outlinesTouch(outlines[to], outlines[from], tolerance) {
+				m.Territs[from].Neighbours = append(m.Territs[from].Neighbours, &Neighbour{Name: to})
+				m.Territs[to].Neighbours = append(m.Territs[to].Neighbours, &Neighbour{Name: from})
+			}
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	polygons, err := parsePath("M0 0 L10 0 L10 10 Z m20 0 h10 v10 z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polygons) != 2 {
+		t.Fatalf("expected 2 polygons, got %d", len(polygons))
+	}
+	if last := polygons[1][len(polygons[1])-1]; last != (point{30, 10}) {
+		t.Errorf("expected second polygon to end at (30, 10), got %v", last)
+	}
+}
+
+func TestParsePathRejectsNumberAfterClose(t *testing.T) {
+	if _, err := parsePath("M0 0 L10 0 L10 10 Z 5 5"); err == nil {
+		t.Error("expected number after close to fail")
+	}
+}
+
+func TestImportSVG(t *testing.T) {
+	// West and East share an edge, North only comes within 2 units of West,
+	// and the island is far away from everything.
+	svg := `<svg xmlns="http://www.w3.org/2000/svg">
+		<path id="West" fill="#ff0000" d="M0 0 H10 V10 H0 Z"/>
+		<polygon id="East" points="10,0 20,0 20,10 10,10"/>
+		<g>
+			<path id="North" d="M0 -12 H10 V-2 H0 Z"/>
+		</g>
+		<g id="Far_x20_Island">
+			<path d="M100 100 h10 v10 h-10 z"/>
+			<path d="M120 120 h2 v2 h-2 z"/>
+		</g>
+	</svg>`
+	m, err := ImportSVG(strings.NewReader(svg), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	neighbours := map[string]string{"West": "East", "East": "West", "North": "", "Far Island": ""}
+	if len(m.Territs) != len(neighbours) {
+		t.Fatalf("expected %d territories, got %v", len(neighbours), m.Territs)
+	}
+	for name, neighbour := range neighbours {
+		territ, found := m.Territs[name]
+		if !found {
+			t.Errorf("territory '%s' is missing", name)
+			continue
+		}
+		var names []string
+		for _, n := range territ.Neighbours {
+			names = append(names, n.Name)
+		}
+		if strings.Join(names, ",") != neighbour {
+			t.Errorf("expected '%s' to border '%s', got %v", name, neighbour, names)
+		}
+	}
+	// The island's marker sits on its larger outline.
+	if center := m.Territs["Far Island"].Center; center != "105 105" {
+		t.Errorf("expected the island's center at 105 105, got %s", center)
+	}
+	if center := m.Territs["West"].Center; center != "5 5" {
+		t.Errorf("expected West's center at 5 5, got %s", center)
+	}
+	if color := m.Territs["West"].Color; color != "#ff0000" {
+		t.Errorf("expected West's fill as its color, got '%s'", color)
+	}
+
+	// With a larger tolerance, North borders West.
+	m, err = ImportSVG(strings.NewReader(svg), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.IsAdjacent("North", "West") {
+		t.Error("expected North to border West")
+	}
+}
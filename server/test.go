@@ -1,266 +1,25 @@
 package main
 
 import (
-	"sync"
+	"math/rand"
 )
 
-func NewTestGameHongKong(m *Map) *Game {
-	state := NewGameState("hk")
-	state.AddPlayer("wahtever")
-	state.AddPlayer("hawflakes")
-	state.Start(m)
-	return &Game{
-		lock:  sync.Mutex{},
-		state: state,
-		m:     m,
+func NewTestGameHongKong(m *Map, store Store) (*Game, error) {
+	game, err := NewGame("1", DefaultGameSettings("hk"), m, rand.Int63(), store)
+	if err != nil {
+		return nil, err
 	}
-}
-
-func NewTestMapHongKong() *Map {
-	m := &Map{
-		AssetPath: "/maps/hk",
-		Territs: map[string]*Territory{
-			"Yuen Long": {
-				Neighbours: []*Neighbour{
-					{
-						Name: "Tuen Mun",
-						Path: "M259.4,405.1c48.9-42.3,182.1-113.1,182.1-113.1",
-					},
-					{
-						Name: "Tsuen Wan",
-						Path: "",
-					},
-					{
-						Name: "Tai Po",
-						Path: "",
-					},
-					{
-						Name: "North",
-						Path: "",
-					},
-				},
-				Center: "487 277",
-				Color:  "#72ab76",
-				Paths: []string{
-					"M569.6,97.2l-3.8,1.8l-2.8,5l-4.1,2l-1.2-0.9l-11.2,9.4h-3.9l-9.3-5.9l-8.2,0.3l-15.5,6.8l-7,7.9l-2.8,15.2l-5.5,5.9l-13.6,3.6l-13.2-4.3l-6.8-0.7l-7.2,3l-9.7,9.8l-1.1,2.3l-3.2,1.4l-1.4,3.4l-4.7,4.5l0.2,8.8l-0.9,2.2l1.4,7.5l-1.1,4.8l9.8,5.9v2.1l-6.8,0.2l2.5,17l4.6,0.3l0.2,1.8l-5.2,3.2l-3.2,4.7l-1.4-0.4l2.8-8.4l-2.5-7.9l-4.6-8.6l-2.5,2.8l-1.6-0.3l-4.5-1.4l-10.7,2.3l-6.8,6.1l-4.8,6.4l-3.4,0.5l-2.3-2.2l-9.5,5.7l-2.7-1.2h-10l-7,5.9l-5.4,10.2l-3.6,13l3.4,10.4l3.6,9.5l-1.8,1.8l-7.5-15.5l-0.4-13.2l5.9-14.8l9.5-10.7l13.8-1.4l5.5-0.5l3.4-3.6l2.9-5.9l1.2-19.5l2.5-2.3l-3.2,0.2l-5,1.6l-4.8-3.6l-8.6-2.7l-11.1-1.1l0.4,3.8l-6.1,5.3l-2.7,7.5l-2.9,10.9l-9.8,7.3l-10.4,9.1l-1.4,3.8l-8.2,10.9l5,2.8l1.6,2.2l-9.6,0.5l-4.1,12.2L302.2,265l-10.3,1.6l-6.6,3.8l-0.3,3.8l-3.8-2.7l-7.2,4.1l-5.3-1.1h-3l-3.4,9.1l-2.2,12.3l-6.2,2.5l-7.3,8.4l-1.4,1.2l0.6,8.6l-3.8,8.9l-3.1,3.6l-13.4,8.2l2.5,2.5l0.2,8.4l-1.1,0.9l-0.2,5.6l2.8-0.8l4.1-5.6l4.5-1l-0.2-4.3l5.1-2.8l1-5.8l6.8-12.9h46.2l1,6.1l35.6,1l1.8,2.2l4.3-2.2l4.8-2.3l4.3-2.8l3.8,1.2l5.1-2.2l-5.6,13.4l7.3,12.4l21.7,1l0.8,16.4l10.1,1l0.8,23.7l88.6,0.3l0.2-8.3l20.2-0.2l-0.2,8.8l-6.8,0.2l0.2,18.7l18.4,1l1,16.4l10.6-6.3l8.3-0.5l2.5-9.3l11.1,0.2l9.8-9.3l4.3-3.5l6.8,1.8l5.8,4.3l3.5-0.2l4.8-4.3l12.6-14.7l8.6-0.5l2.8,4.8l7.1-1.2l8.6-1.8l0.8-3l6.1-3.8l3.3-2.8l12.6,2.5l0.8-5.8l4.3-5l0.5-14.4l-8.1-11.9v-4l-7.8-5.8l-3.3-8.3l-0.8-6.8l-9.3-19.7l0.8-7.8l10.6-12.1l7.3-10.1l3.5-1.2l0.8-6.6l9.8-10.6l3.8-10.1l-14.9,0.2l-2.8-6.3l-5.5-0.5l-4.6-6.8l-11.9,0.8l-5.8,4.5l-8.6-2l-8.8,6.1l-9.1,2.5h-14.9l-0.5-37.9l6.6-11.3l0.5-17.2l-5-7.8l0.8-6.3l2.3-4.3l-1.8-11.1l-3-0.8l1.2-9.3l4.1-0.2l2.2-4l-3.3-3.5v-5.6l-2-3.3l3-10.6l-0.5-3l-4.1-1.8L569.6,97.2z",
-				},
-			},
-			"Kwun Tong": {
-				Neighbours: []*Neighbour{
-					{Name: "Sai Kung"}, {Name: "Eastern"}, {Name: "Kowloon City"}, {Name: "Wong Tai Sin"},
-				},
-				Center: "888 608",
-				Color:  "#c23d00",
-				Paths: []string{
-					"M834.7,565.9l0.2,22.7l-5.5,3.9l17.5,19.7l-0.9,3.6l2.6,2.1l5.3-3l20.9,21l5.8-3.8l3.3,3l-5.6,5.3l3.8,5.1l2.5,1l1.3,5.8l6.8,5.3L903,656l3.8,4.6l-8.6,5.5l8.3,10.1l2.8-2.8l4.3,3.5l-4.1,4l0.5,2l5,4l2.8,0.4l1.2-3.5l-2.8-3l2.7-4.5l0.2-12.3l6.8,2l0.2-8.2l-4.3-11.2l-6.6-6.4l-1.4-6.1l9.1-8.4l2.3-9.1l-8.2-2.5l3.6-8.4l-1.1-6.6l-4.8,3.8l-14.8-8l-5.2-12.8l-7-13.8l-32.7,3.4l-6.6-5.7l-8.2,2.5L834.7,565.9z",
-				},
-			},
-			"Yau Tsim Mong": {
-				Neighbours: []*Neighbour{{Name: "Kowloon City"}, {Name: "Wan Chai"}, {Name: "Central & Western"}, {Name: "Sham Shui Po"}},
-				Center:     "755 625",
-				Color:      "#fc7a1e",
-				Paths: []string{
-					"M734.9,585l1.1,4.8l-4.5,1.1l-5.2,5.7l-7.7-7.7l-3.9,4.6l6.4,7.4l0.8,14.7l7.3,27.8l-2.5,2.8l-5.1,0.5l-0.5,7.1l6.8,0.5l1.8-1.8l9.1-2l0.2-7.6l6.1-2.8l4.5,5l0.2,19.7l4.8,5.3l9.6,1l1-1.5l0.8-3l4.8-6.1l7.3-4.3l1.8-3.5h2.8l1.5,2.8l4.7-1.6l-0.3-6.1l-5.7-3.4l0.2-3.8l-4.7-9.8l-10.3-11.6l-7.7-25.9l3.6-8.4H734.9z",
-				},
-			},
-			"Sham Shui Po": {
-				Neighbours: []*Neighbour{{Name: "Kowloon City"}, {Name: "Yau Tsim Mong"}, {Name: "Kwai Tsing"}, {Name: "Sha Tin"}},
-				Center:     "732 561",
-				Color:      "#a93500",
-				Paths: []string{
-					"M739.3,532.3l-7.5,2.7l-3.4,5l-7.3-3.2l-5.2,1.4l-3.6-2.5l-3.6,5l-6.6,0.2l-0.5,4.6l3.8,3.2l-7.5,3.9l-3.6-2.2l-6.6,3.4l-8-4.3l-5.7,1.1l-1.4,3.2l-7.2-1.4l2.3,6.1l5-0.7l3.2,1.6l-0.5,7.5l11.2,4.1l-0.7,10.5l-13.1,12.1l-4.8,2l-3.8,4.8l3.8,5.1l-0.1,3.5l2.7,2.2l2-3.5l9.9,0.8l4.3,0.2l4,6.3l2.8-3.5l2.8-1.3l1.8-5.8l6.1-4.8l-3-4.6h-4.1l-3.5,4.1l-5.3-4.8l2.5-4.3l-4-3l2.3-4.3l5.3,2.3l1.5-3.8l18.9,12.1l4.1-4.5l7.8,7.8l5.7-6.2l3-1.1l-0.9-4.3h30l4.8-12l-0.4-11.2l-3.9-10.2l-3.8-3l-1.8-2.8l3.1-4.5l-1.6-3l-4.6-1.4l-4.5,0.7h-7.2L739.3,532.3z",
-				},
-			},
-			"Kowloon City": {
-				Neighbours: []*Neighbour{{Name: "Wong Tai Sin"}, {Name: "Kwun Tong"}, {Name: "Eastern"}, {Name: "Yau Tsim Mong"}, {Name: "Sham Shui Po"}, {Name: "Sha Tin"}},
-				Center:     "794 594",
-				Color:      "#da4400",
-				Paths: []string{
-					"M776.7,530.9l-11.6,0.5l-4.3,5.9l2.5,3.9l-3.9,5.2l6.2,5.9l3.6,10.9l-0.2,12.3l-8,17.5l0.9,6.1l7.3,20.6l8.9,10l5.3,10.7v3.8l5.4,3.4l0.2,6.5l1-0.3l15.2-7.1l2.5-9.1l-1.3-4.6l-2.2-3l-0.5-12.9l8.8-10.3l-1.8-2.3l2-3h3.6l42.7,41.9l2.8-2.8l-4-3.5l-0.2-4.3l-3.5-2.8l-1.8,1.5L838,616.2l6.3-1.8l1.3,1.1l0.8-3.4l-17-19.5l4.8-3.9l0.7-22.7l-7.3-2.3l-16.6,10.5l-4.5-2.1l-5.3-4.5l-5.9,3.9l-3.8-10.7l-1.1-3.9l-5.4-4.5l2.2-7.3l-3.2-3.9l-3-0.5L776.7,530.9z",
-				},
-			},
-			"Sha Tin": {
-				Neighbours: []*Neighbour{{Name: "Tai Po"}, {Name: "Sai Kung"}, {Name: "Wong Tai Sin"}, {Name: "Kowloon City"}, {Name: "Sham Shui Po"}, {Name: "Kwai Tsing"}, {Name: "Tsuen Wan"}},
-				Center:     "842 428",
-				Color:      "#64956a",
-				Paths: []string{
-					"M916.8,311.2l-9.3,3.2l5.7,4.7l0.7,4.6l-16.1,11.8l-3.6-2.8l-7.2,2.8l-9.3,15l-4.6,18.9l-5,15l6.4,6.8l-2.8,1.8l-6.4-5l-33.9,38.2l21.1,19.3l-5.7,0.7l-18.9-16.4l-25.7,27.1L781,469.4l-9.3-11.4l-27.5,10v-3.9l24.6-9.7h5l7.2,9.7l15-7.2l27.2-29.3l-7.9-10.3l3.2-1.1l7.8,6.8l35.4-40.3l-8.9-7.8l0.4-14.3l3.2-5.7l-3.2-7.2l-13.4-11.3l-0.9,2.5l-7.8-0.6l-2.7,7.1l-3.9-7.6l-6.3,20.5l-29.2,0.1l-1,5.6l-26.1,1l-1.5,5.7l-11,1.5l-5.2,6.2l0.8,5.7l3.9,5.9l-0.2,10.9l-3.2,5.6l2.4,10.8l-9.7,8.2l-5.9,11l-6.7,3.2l-2.9,5.6l-16.4,8.1l0.8,4.2l-5.3,6.7l-3.8,1.5l-1,6.2l5,5.9l-4.3,2.4l-1.5,7.7l3.6,8.1l-11.4,13.7l2.4,21.6l6.7,0.9l0.6,5.1l4.3,4l5.3,0.1l4.3-5.3l4.4,1.5l4.9-1.3l7.3,3.8l3.4-4.8l7.4-2.8l5.1,5.6l16-1.2l5.9-5.4l10.5-0.2l9.6-3.3l9.1,0.6l7.5-1.4l-0.5-6.2l5.7-1.5l5,3.7l2.5-0.8l5.8-7.7l4.7,1.9l25.9-3.3l1.4-4.3l12-3.5l9.7-0.6l0.5-5.8l-0.9-5.4l9.8-19l10.6-1.1l20.3-13.5l3.2-6.7l4.8-2.4l5.7-11l-0.2-3.8l15.8-1.5l0.8-3.8l-3.5-3.5l0.5-21.5l2-5.5l-1.2-10.1l-4-5.2l1.2-6.6l-3.7-7.8l-5.2-0.9l0.8-18.2l2.5-3.9l-1.1-12.2l-4.6-1.5l2.8-5.2l0.3-3.8l-4.9,2.6l-5.4-6.4l5.7-4.7h-4.3L916.8,311.2z",
-				},
-			},
-			"Wong Tai Sin": {
-				Neighbours: []*Neighbour{{Name: "Sha Tin"}, {Name: "Sai Kung"}, {Name: "Kwun Tong"}, {Name: "Kowloon City"}},
-				Center:     "841 539",
-				Color:      "#912e00",
-				Paths: []string{
-					"M776.3,531.1l4.3,5.9l0.4,3.5l5.2,3l1,3.3l-1.4,6.8l5.6,3.4l1,7.7l2.3,2.8v3.2l2.7-0.1l4.7-3l4.8,3.9l0.9,1.9l4,0.3l14.3-8.7l6.2-0.3l8.2,3.8l8.5-2.4l8.2,6.1l18.9-3.8l-5.9-49.9l2.1-13.1l-9.6-0.4l-9.5,4.8l-1.9,4l-14.5,0.6l-12.1,1.6l-2.7-2.3l-6.2,8.3l-4.9-0.1l-2.9-2.5l-5.4,1l0.6,6.2l-10.5,2l-5.7-0.9L776.3,531.1L776.3,531.1z",
-				},
-			},
-			"Eastern": {
-				Neighbours: []*Neighbour{{Name: "Kwun Tong"}, {Name: "Southern"}, {Name: "Wan Chai"}, {Name: "Kowloon City"}},
-				Center:     "883 724",
-				Color:      "#015994",
-				Paths: []string{
-					"M831.9,670.3l-8.8,0.5l-8.4,2.3l-6.5,4.6l-1.8,1.2l-2.5-0.9l-6.4,6.1l-0.5,2.4l3.5,0.9l-0.4,3.7l-8.5,2l-3.2,2.5l1.8,0.4l0.3-0.9l0.9,0.2l-0.5,1.5l0.9,0.1l3.4,7l3.8-3.8l6.6-2l3.2,3.4l4.8,10.6l5,6.6l6.1-3.6l2.9-5.9l11.1,13.4l-9.6,10.2l-8.9,7.2l-0.4,17.2l6.2,0.2l7-2.7l4.8,1.8l6.6-3.2l11.6-2.5l3.2,3.2l5.4,3.4l24.8,2.9l4.7,5.5h7.3l4.5-4.8l14.8,2.8l4.7,3.9l5.7,2.1l5.7,13l1,1.1l0.5-1.8l0.6-1.6l3.8-1.3l1.9,0.6l4.1,0.2l-0.9-2.1l0.1-5.2l2.3-1.1l0.2-6.4l2-2.5l0.3-3.2l-2.7-4.5l-0.5-4.9l4-3.2l-16.8-15.7l-3.8,1.8l-4.8-4.1l-5.9,2.4l-3.4-5.2l4.5-5.2l-5.5-8.4l0.7-3.3l-2.2-3.1l-3.6-1.4l-1.2-5.1l-4.3-2.4l-2.8-3.5l-2.1-1.4l-2-0.2l-2.5,2.7l-14.8-0.4l-2.5-4.2l-9.2-9l-5.7-2l-3.1-1.2l-3.4,0.5l-12-5.5l-9.1-0.2L831.9,670.3z",
-				},
-			},
-			"Central & Western": {
-				Neighbours: []*Neighbour{{Name: "Yau Tsim Mong"}, {Name: "Wan Chai"}, {Name: "Southern"}, {Name: "Lantau Island"}},
-				Center:     "718 716",
-				Color:      "#0164a7",
-				Paths: []string{
-					"M687.8,677.8l-9.1,2.5l-5.9,3.9l-6.1,0.9l-11.4,9.1l-18.6,7.7l-2.5,3.2l-0.2,6.1l1.2,4.5l3.4,4.4l0.6-0.2l2.8,2.2l6.6-0.7l11.9-5.5l14.3,5l18.2,28l1.6,5.2l3.2,3.2l0.7,7.5l2.1,3l11.6-6.1l3.9-9.6l3.6-1.6l6.4,5.6l14.3-2l6.4-13.4l-0.5-17.1l5.2-7.9l-0.5-8.8L750,705l-0.5,0l-4.6,0.2l2.7-3.8l-2.7,0.7l-2.8-1.1l-0.6-3.1l-5.5-0.9l-2.7-2.5l-2.5-2.7l4.7-7.2l-12.5-3.9l-5.2,3.6l-8-3.4l-9.1-3.2L687.8,677.8L687.8,677.8z",
-				},
-			},
-			"Tuen Mun": {
-				Neighbours: []*Neighbour{{Name: "Yuen Long"}, {Name: "Tsuen Wan"}, {Name: "Lantau Island"}},
-				Center:     "261 410",
-				Color:      "#81c185",
-				Paths: []string{
-					"M297.9,321.9l-48.2,0.2l-6.3,14.4l-0.9,4.6l-4.7,2.1l0.4,4.1l-5,2.1l-5.4,6.7l-1.5-3.5l1.3-2.2l-0.2-1l-0.2,0.2l-3.9-4.1l-5,0.5l-3.6-3.2l-9.5,3l0.3,6.8l-4.6,2.8l-0.2-7.7l-12,0.3l-10,4.1l-6.8,1.1l-4.5,11.8l-3.4,0.3l-14.7,13.8l-4.1,7.9l-2.2,2.3l7.2,2.3l4.1,2.3l5.3-1.4l3.4,2.5l3.4,0.7l6.6,5.2l1.4,8.6l-4.1,4.5l1.8,4.4l0.9,3.4l8.2,3.9l3.9,7.8l-0.2,14.1l-3.8,9.8l-2.9,1.4h-3.6l-2.3,3.6l2.5,5.2l-2.2,2.1l3.8-0.7l13,11.6l6.4,3.8l3.2-0.9l3.4,1.4l6.4-3.6l5.2,0.7l3-1.8l6.4,2.9l4.8,1.2l6.2,7l13.2,0.5l8-7.3l0.2-3.4l6.4-5.7l2.8-3.2l6.6-1.8l3.4,1.4l24.5,5.5l1.4-2l-10-22.5l0.3-9.7l5.3-8.9l3.9-12.5l0.4-13.9l5.7-5.3l1.8-9.3h2.8v8.2l-3.9,8.9l-3.6,18.2l-7.8,17.1l-1.1,6.1l2.5,3.6l13.2-5l2.9,5h5l2.1,5.7l12.5,6.1l7.5,8.6l6.1-1.8l4.3,3.6l7.1-5.4l-2.1,7.2l-7.9,6.4l2.2,5l7.5-1.8l5.3,8.2l0.7,2.9l6.8-2.5l11.4-7.5l9.6,1.1l-2.1,5.2l1.5,1l2.5-1.5l8.6,3l12.4-12.9l-2.5,10.1l-6.8,7.3l4.1,4l0.2,2.3l16.4,5.6l1.4,0.1v-10l14.1-8.9l2.2-7.3l5.8-1.7l5.6-3l1.8-3.2l-3-2.3l-3.8,1.9l-3.8-0.7l-4.9-4l-1.1-7.7l-5-1.1l-0.2-5l6.1-4.1l3.7,1.3l3.7-2.7l-1.1-3l2.9-1l2.8-3.7l4.5,2.8l6.8,1.5l3.4,2.3l3.3-0.6l0.8-3.7l-1.9-3.8l-0.2-6.3l5.9-2.2l12.8,5.4l4.2-4.4l18.6,0.4l2-15.3l0.1-15.3l-19,0.1l0.1-18.7l6.7-0.4l0.4-8.8l-19.9-0.3l0.1,9.1l-89.6-0.5l-0.1-24l-10.5-0.2l0.1-17.1l-15.5-0.1l-3-1.2l-3.4,0.9l-6.7-9.2l-0.4-5.2l5.3-12.2l-5.8,3l-4.2-1l-7.1,4.7l-6.1,1.4l-4.2-2l-3.7,0.7l-3.5-1l-25.7,0.4L297.9,321.9L297.9,321.9z",
-				},
-			},
-			"Lantau Island": {
-				Neighbours: []*Neighbour{{Name: "Tuen Mun"}, {Name: "Tsuen Wan"}, {Name: "Central & Western"}, {Name: "Southern"}, {Name: "Lamma Island"}},
-				Center:     "271 739",
-				Color:      "#b38b3e",
-				Paths: []string{
-					"M386,614.2l-3.7,3.2l-26.3-0.1l-0.9,1.2l-9.3,6.8l-3.1,1.2l-4.1-0.9l-18.1,9.7l-6.1,9.1l-6.5,8.1l-37.3,9.6l-5.9-0.9l-17.8,1.8l-8.3,5.2l-7.8,2.2l-7.7,7.6l-2,6.9l-1.7,0.9l-1.6,2l4,1.1l1.9,2l-3.3,0.9l-6.1,3.8l-1.2,8.3l-0.8-2.5l-4.7,1.9l-2.5,4.2l-1.9-3.9l-2.1,0.4l-2.8-8.1l-3.9-5.1l-2.1-6.3l0.4-3.9l-8.8-2.9l-2.2-1.8l-5.8-2.4l-8.4,2.1l-4.9,3.7l-4.8-0.1l-1.8-3.3l-7.7-3.2l-5.9,8.2l-4.4-1.8l-1-2l-2.7,0.6l-3-1.4l0.1,3.9l-3.8,2.2l-0.2,4.1l-2.7,12.2l-7.2,6.3l-9.7,4.8l0.1,9.1l3.2,3.2l-0.5,11.9l-8.9-4.7l-1.4-4.8l-11,5.7l-5.8,3l-5.4,0.8l-3-0.5l-5.3,3l-0.4,5.8l-3.9,3l-3.8,5.4L60,756l-1.8,0.8l-2.8,1.3l-0.1,3.5l0.8,2l4.3,1.2l6.1,3.3l2.3,3.5l0.8,6.7l1.4,2.8l-0.5,1.8l-2.2-1.7l-2.3-6.6l-3.5-4.3l-4.4-1.8l-3.6,0.6l-5,3.2l-1.4,4.3l1.9,8.2l-1,3.3l-3.2,2.5l-12.5,1.7l-5.4,2.9l-0.6,5.3l2.8,5.1l0.1,12l-0.9,3.7l-4.9,5.4h-3.2l-1.3-5.4l-5.9-5.9l-3.4,2.4l-1.8-1.4l-4.3,3.9v7.9L0,831.4l0.2,3.5l1.1,1.2l-0.1,15.7l1.3,2.5l-1.3,2l4.6,1.5l2.1,2.4l2.7,2l0.4,2.9l-2,1.7l-1.3,4.4l-1.4,1.3l-1.2,0.1l-0.5,3.8l1.3,1.9l2.8,6.1l5.2,5.2l6.6,1l2.2,2l1.2,4.4l-1.7,2.9h-5.2l-1,2.4l1,1.5l-1.2,4.7l-2.4,2.5l-0.8,2.4l1.9,0.6l2-1.1l1.3-4.1l5.7-0.1l4.7,0.2l3.7-0.8l-0.8-1.7l-3.5-3.2l1.8-4.7l7.8-5.3h8.3l2.3-1.8l5.2-0.4l1.9-0.4l1.4,0.7l0.1,3.7l-1.2,1.2l1.4,0.9l7.4-3.2l0.2-2.9l5.1-7l2.9-4.9l5.6-4.6l8.5-0.5h3.8l-2.3-0.9l-1.8-2.5l2.4-3.5l1.9-1.8l2.7-1.5l2-5.1l3.2-5.2l8.7-3.9h5.2l1.4,2.9l4.6-5.7l2.4-2.2h3.5l3.3,1.9l8.4,3v3.8l-3.7,4.2l2.7,3l1.4,4.4l7.9,5.4l6.1,3l10.6,10.1l4.6,2.5l4.5-3.7l0.7-5.1l2-4.3l3-1.2l2.7,2.2l3.5,1.9l1.4,1.4h2.5L184,882l2.9,0.9l2.2,0.4l5.8-4l5.9-0.2l6.1-2.4l1.4-7.6l0.6-4.3l-2.3-3.3h-2.1l-2.4-3.2l-4.6-0.9l-3.7,3.9l-4.8,0.2l-6.6-2.3l-5.1-3.2v-1.6l2.4,0.9l0.4-3.8l2.7-3.2l3.4-0.4l1.4-0.7l1.5-8.3l4.7-2.7l3.4,0.4l2,3.4l3.4,0.6l-0.5,1.4l5.4-1.8l7.1-2.9l0.8-4.2l3.8-2l6.3,0.1l3.3-2l5.5-5.1l3.4-0.5l16.1-6.7l8.4-1l6.1,3l1.4,3.8l1.4-1.8l6.7-2l2.4,1l-0.8-5.4l5.3-2.9l5.9-1.1l7.6-6.1l5.6-2.8h2.2l9.9,2.7l3.7,2l-0.8-1.8l0.2-5.9l-0.8-1.5l1-0.9l2.2,2.8l2.7,5.8l2.5,1.2l-0.4,3.5l0.5,4.6l-3.9,2.9l-2.3,2.7l-1.9,8.3l-2.2,1.3l-0.4,1.1l3.7,2.3l3.4,4.3l3.8,2.9l4,4.8l-0.6,3.9l-2.9,0.6l-0.9,5.3l-3.3,1.9l0.6,4.1l6.7,2.4l4.4-0.5l3,4.3l-1.2,4.2l6.1-0.8l-1.6-3.9l4.9-2.5h5.1l2.9-2l0.1-2.5l2.3-2.8l5.9-0.1l1.2-1.1l4.4,8.7l8-0.6l1.9-6.4l11.8-2.9l-0.1-4.8l5.3-6.6h4.2l6.6-3.2l1.4-6.8l5.8-4.7l-12-2.5l-8.6-3.8l-0.5-5.6l-2.8,0.5l-3,2.8l-4,1.2H377l-1.5-2.3l-0.8-6.1l-3.8-4l-4.3,0.2l-3.8-6.3l-1,2h-7.6l-3.3-2.5v-4.6l12.4-7.8l4.5-4.8l-0.8-2.5l4.3-1.5l2.3,0.8l3.8-0.8l12.6-9.1l3.8,1l0.5-3.3l-6.6-3.5l-2.8-3l-1.8-6.3l-6.1-6.1l-9.8-2l4.8-3l-2-5.1l-6.8-3.3l-3.5,2.8l-7.3-1.3l9.3-5.8l1-4.6l6.3-5.8h5.5l3.6,3.3l4.3,3.8l6.8,2.2l2,2.3l1.5,3.5l4.8,2.5l4.8,0.2l9.1-3l5.3,0.8l-0.5-5l1.2-0.8l-0.5-3.8l-2.3-0.8l-2-1l-0.5-3l1.8-2l5.3-3.3l-4.3-4.3l1.3-4.8l3.8-2l-3.8-4.8l1.8-4.8l1-3.5l-4.3-1.2l-4-3.3l-1-4.6l0.2-6.1l2.5-2.2l-2.8-4.3l0.5-2.5l2-0.5l4,2l5.6,2.8l-1.8-3.5l-0.8-3l1.5-0.8l4.6,0.8l-0.5-6.1l-2.8-3.8l-6.6,5.8l-1.3,2.5l-6.1-0.2l-1-2l-4.6-0.8l-3.3-1.8v-5.1l4.1-3l3.5,0.2l5.3-3.5l-2.3-1.5l-5.3,1.3l-3.8-2.3l-2.3-4.8l-4-0.2l9.3-5.6l0.8-4.8l2.3-1.5l0.2-3.3l1.8-1l4.5,1.8h10.1l4.3-1.5l2-4l3.3-0.5l5.6,2.3l3.8,5l7.6-2.5l-0.5-2.5l-2.4-5.8l0.1-1.1l-57.1,0.2L386,614.2z",
-					"M121.5,628.8l92.7-33.1l5.3,7.8l22-6.8l0.3,3.3l-2.3,4.5l-3,2.5l-1.5,8.3H228l3,7.8l-4.3,5.6l0.5,13.6h2.3l-1.3,4.3l-5.3,0.8l-2.5,3.5v4.8l4,10.6l-9.6,11.1l-38.1-11.9l-17.9-1.3l-16.7,1l-10.1,3.5l-5.6-3.8l9.6-14.9l-5.3-14.6L121.5,628.8L121.5,628.8z",
-					"M153.3,935.4l-2.1,7.9l2,2.7l-1.6,6.6h5l4.8-5.2l5.5,0.7l3,3.8l-2,3.2l2,0.5l5.4-4.6h5.7l-0.5-2.3h-5.2l-2.9-3.9l-5.5,1.2l-4.6-2.3l-0.2-3.8L153.3,935.4L153.3,935.4z",
-					"M146.9,1010.8l3.8-2.3l1.2-4.6l10.9,0.5l8.4-2l-1.1-6.1l-3.6,0.7v-3l0.4-8l9.5-1.2l-4.6-5.2l-3.2,0.7l-6.2-1.6l-4.3,5.7l3.9,2.9l-0.4,4.1l-3.4,3l-2.9-1.1l-0.7,4.3l-4.1,0.2l-4.5-3.2l-4.6,1.2l-5.2,5.5l1.2,3.2l4.8,2L146.9,1010.8z",
-					"M343.2,929.1l10-4.9l0.6-5.1l1-2.4l-1.6-1.8l-4.3,2.8l-2.1-3.8l2.4-5.1l-2-4.3l-7.2-6.9l-2.9,1.9l-3.2,1.4l-4.7,6.8l-0.8,4l-2,4.3l7.4,5.7l5.1,2l1.5,3.5L343.2,929.1L343.2,929.1z",
-					"M417.4,913l3.5-2.7l4.8-2l1.4-3.4l4-0.4l4.8-7.1l10.5-2.7l4.3,4l3.8-3.9l2.5-0.6l0.1-4l1.9-1.6l-2.5-5.8l-6.4,0.6l-0.9,2.4l-7.3-1.6l-4.8-4.5l-3-7.2l1-4.8l4.9-5.2l8.1-3.9l-6.4-0.3l-0.1-3.5l6.3-4.5l-1.4-3.4l-2.5,0.1l-2.1,1.6l-9.5,0.5l-5.2,2.9l0.4,5.7l1.6,1.1l-0.6,3.3l-3.5,7.4l-6.2,0.6l2.9,4l5.6-0.6l5.8,4.8l0.3,8.2l-2.7,3.7l-8.3,0.8l-3.9,7.6l-1.3,3.8l-2.9,0.1l-1.8-1.9h-3.3l0.3,2l3,3l-2,2.1l1.4,2l5.3-1.6l0.6,2.4L417.4,913L417.4,913z",
-					"M465,691l1.3-2.9l5.6-0.4l-1.9,3.8l3,2.3l-0.4,3.3l-1.9,2.7l-3.7,2.5l-9-0.9l-2.7-2.8l0.3-5.3l-3.4-2.8l-3.4-9.3l2.8-2.8l14.5-4.3l2.8,3.2l-2.9,1.9L461,682l-1.5,2.7l-1.9,0.6l-0.5,3l2.7,3L465,691L465,691z",
-					"M482.6,761.1l4-5.4l0.9,0.4l3.4-1.5l0.2-3.6l2.3-2.1l0.2-3l-3.2-3.9l-6,1.5l-4.1,2.6l-5.6,2.9l2.2,3.8l-1.8,2.6l-0.3,3.1l2.8-0.4l3.7,2.8L482.6,761.1L482.6,761.1z",
-					"M468.8,799.5l3.1-3.8l2-9.2l-6.2-7.9l3.3-13.3l-5.5-6.5l-19.6-1.1l-8.4,1.6l-5.1-2.1l-1.4,1.8l2,1.9l-4.1,2.4l1.7,3.8l12.6,1.3l2.7,6.4l3.2,4.7l1.7-3.1l5.4,3.4l-1.2,5l4.5,6.5l1.6,7.2l3.8-0.8L468.8,799.5L468.8,799.5z",
-					"M34.4,771.2l3.6-2l0.2-3.9h2.5l9.1,1.4l2.5-2.1l-2.5-5l2.5-3.2l-4.6-6.2l-8.4,4.1h-3.6l-3.6,6.1l-3.2,3l3.2,7.7L34.4,771.2L34.4,771.2z",
-				},
-			},
-			"Lamma Island": {
-				Neighbours: []*Neighbour{{Name: "Lantau Island"}, {Name: "Po Toi"}, {Name: "Southern"}},
-				Center:     "681 955",
-				Color:      "#e6b350",
-				Paths: []string{
-					"M681.1,955.4l1-9.1l-8.8-11.9l-0.3-8.6l7.3-3.5l2.8,4.5v3.8l6.8,2.3l7.3,9.3l7.1,1.8l3.3,2.8l0.8-2l-6.6-8.3v-4l-4-5.6l-5.6-1l3-6.1l-3.8-7.1l-2.3,2.3l-3.8-5.8l6.1-6.8l1.3,2.5l8.1-3.3v-5.3l6.6-5.1l0.5-2l7.6-4l3.5,6.1l7.8-0.5l0.5-4.8l5.8-3l-4-4.8l-4-0.3l-3.5,2.3l-7.8-1.8v-4.8l-3-1.5l-4.3,3l-1.8-0.3l-1.8,1.8l0.3,2.3l-3.3,4.3H694l-2.3-2h-12.1l-6.8,2.5l-4,5.1l-3.3,8.1l-3.5,1l-5.3-7.3l-0.5-10.1l-0.8-2.8l3-3l3.5,2.8l5.6-3.5l11.4-11.6l-0.8-3.8l-0.8-5.8l-5.3-3l-3.3,3.8l-2.5,3l-3.8,2.5l-7.1-8.1l-2.8-2.8l1-5.8l-1.3-3.3l-0.5-1.8l-9.3-6.6l-1.5-8.8l5.6-6.6l-2.3-8.1l-6.8-3.8l-2.3,3l-16.9,6.8l-8.3,3v4.5l9.9,4.8l-3.9,4.6l4.3,5.7l1.4,5l-6.4,2.9l-11.4,10.7l-0.7,6.1h22.5l2.1-9.3h6.1l1.8,4.3h6.1l1.4,7.1l2.5,3.2l-1.4,4.3l1.8,4.1l-1.8,2.7l1.1,4.8l-4.1,1.8l6.2,7.7l-1.1,3.2l-0.5,12.3l-3.2,6.1l0.9,4.5l-1.1,8.9l-4.5,3.4l-9.5,7.7l-0.4,4.3l5.2,6.4l8.2,3.2l5-1.1l1.2-2.1h2.1l5.4,4.6v2.5l3.8-1.1l1.8-3l3.2-2.3l2.9-4.3l5.7-1.4l0.2,4.1l2.5,4.6L681.1,955.4L681.1,955.4z",
-				},
-			},
-			"Po Toi": {
-				Neighbours: []*Neighbour{{Name: "Lamma Island"}, {Name: "Southern"}},
-				Center:     "951 1010",
-				Color:      "#cc9f47",
-				Paths: []string{
-					"M926.9,949.1l7.3-1.1l8.8,4.3l9.6-6.7l0.6-7.1l0.8-4.3l-5.4-5.8l-4.5,1.5l-8.1,0.9l-4.5,2l-6.4,0.6l1.9,4l-2,9.2L926.9,949.1L926.9,949.1z",
-					"M951,1010.2l11.1-5.3l0.4-5.1l11.7-11.5l2.9,4.1l14.4,1.1l1.4-4.7l-4.6-8.4l-1.2-5.5l1.7-1.8l0.4-7.6l-5.2-7.5l-6.9-3.4l-3.3-5.5l-3.8,3.4l0.4,4.9l-7.2,1.4l-4.5,1.9l-4.5,3.8l-0.9-0.8l-2.7,3.4l-0.3,1.6l-6.8,7.1l-2.1,4.3l-1.5,4.7v4.9l5.4-1.9l3.3,0.5l-1.7,4.6l-3.1,2.8l5,1.4l7.5,1.2l0.4,5.4l-2.4,2.1l-2.9,2.9L951,1010.2L951,1010.2z",
-					"M1017.2,947.2l7.6,2.1l2.8-1.9l0.3-14l0.3-1.2l-3.9-4.6l-2.3,1.6l-0.2,6.4h-2l-0.9-1.1l-2.3,0.9l-1.3,2.7l1.6,2.1l-0.7,2.2l0.9,2.2L1017.2,947.2L1017.2,947.2z",
-				},
-			},
-			"Southern": {
-				Neighbours: []*Neighbour{{Name: "Eastern"}, {Name: "Central & Western"}, {Name: "Wan Chai"}, {Name: "Po Toi"}, {Name: "Lamma Island"}},
-				Center:     "844 798",
-				Color:      "#1a7dc0",
-				Paths: []string{
-					"M660.7,716.2l-13.1,5.4l-4.7,0.5l-4-2.9l-0.8,0.1l6.6,8.4l11.4,16.3l12.8,21.7l0.3,5.5l3,2.3l6.1,8.1l6.1-0.5l14.4,0.8l22.7,2.8l8.8,0.2l5.3,3l7.1,0.5v2.5l-0.8,1.5l1.2,3.5l2.8,3l-0.2,4.8l-4.3,3l4.8,4.6l3.3,2l-0.2,3l-2,3.3l12.6,7.6l3-1.5l-0.2-4.6l0.8-5.6l3.8-7.3l1.2-4.6l3-2.8l3.3-10.1l5.3-2.2l11.1,0.5l6.3,4.5l-3,2.8l0.5,9.3l4.3,4l6.1,2l5.3-4l3.3,0.2l5.3,3.8l1,5.1l-1.3,11.1l-4,2.5l1.2,2.3l2.3,2v6.1l-3.8,1.5l-3.3-1l1,2.8l4.6,3.3l8.1,2.5l3.3,6.8l-2,4l-7.1,2.5l-2.5,4.5l2,6.8l2.5,0.5l7.1-4.3l9.6-3.5l-0.5-5.1l7.3-6.1l4-1.2l3.8,2l1.5,3.3l4,9.6v3l-4,1l-2.8,6.8l0.5,6.8l-2.3,10.9l1,4.5l-0.8,6.8l1.8,2.5l2.5,2h3.5l18.9-4.3v-7.1l-2.5-4l-2.3-6.6L868,890l0.5-4l-3.3-7.6l1.5-1.5l2.2,1l0.2-6.8l3.6-1l1.5-3l-1-2.8l-7.6-4.3l-4.8-3.3l-4.3-2l-0.5-6.6l2.8-6.6l8.1-8.8l-1.8-1.8l2.8-1.5l2.8,1.5l7.3-10.1l6.6,6.3l6.6-0.8l-0.3-6.6l-1.2-2.3l-1-4.3l-1.5-3.8l-8.3-5.6l-2.3-12.3l-1.8-0.3l-1.5-2.2l0.8-2l4-2.8h2.3l-0.2,3l3.8,4.6l-0.8,3.3l1,3l3.5-1.5l5.3,1l6.3,4.1l2.3-3.3l2.3,2.5l-0.2,10.1l-2,2.8l1.5,2.5l3.3,7.3l-1,7.8l3.8,6.1l-2,2.5l-1,4.3l-1,3.6l5.3,1.2l2,4.8l7.3,2.2l1.6,7.3l2.5,4.3l-0.2,4.5l-2.1,2l-2.6,0.2l0.6,2.1l3,3.8l5.9,2.5l8.9,0.2l3.6-1.6l2.8-0.1l0.7,1.3l4,0.3l2.7-2.5h2.5l2.8,1.7l2.5-0.8l3.1,0.7l0.9-1.6l-2.5-1.3l-0.8-1.2l-3.2-3.3l-2.2-0.2l-3.9,0.9l-1.8-2.3l0.9-3.9l-0.9-4.1l0.8-2.3l-0.3-4.5l-1.6-9l-5-7.7l-2.5-5.5l-1.3-1.5l0.5-3l3.6-2.7h1.1l5.3,2l2.2,0.1l-1.7-2.9l-4.3-1.7l-5.4-1.5l-0.1-2.4l2.1-1.6l3.2-5.1l0.1-2.7l-5.3-6.1l-0.4-6.4l-6.4-4.8l-3.1-6.2l-0.6-3l1.1-2l2.4-0.1l3.2,1.9l1-3.2l-7.3-13.3l-3.4-1.2l-5.9-4.7l-14.5-3.4l-4,3.9l-6.4,1.4l-8.2-6.7l-14.5,0.1l-12.5-3.8l-3.3-4.2l-7.7,0.1l-10.6,4.7l-3.8-1.5l-32.4,6.2l-11.6,5.2l-17.2-7.4l-5.9,5.4l-26.9-10.6l-12.5,2.5l-10.8-5.3l-5.9,11.2l-11,5.4l-2.5-2.9l0.4-5.8l-5.2-5.9l-0.9-4.8L674,720L660.7,716.2z",
-					"M698.5,790.5l12.8-2.1l10,3.2l4.9-2l2.8,0.3l0.5,4.2l-0.6,2.3l4.8,4.8v9.8l-3.3,5.9h-3l-5.6-3.2l-10.9-8.3l-3.2-1.8l-3.4-7.1l-3-2l-2.9-0.6L698.5,790.5z",
-					"M730.7,821.2l1.4,4.9l4,3.5l1.4-0.9l-2.5-5.7L730.7,821.2z",
-					"M785,813.6l-2.1,2.7l4.5,5.9l2.6-0.9v-1.9l8.3-0.1l1.4-2.5l-2.1-1.8l-8.6-0.4l-1.4-1L785,813.6L785,813.6z",
-					"M786.5,860.3l2.1,4.1l0.9,2.9l5.9-3.6l-2.5-3L786.5,860.3z",
-				},
-			},
-			"Kwai Tsing": {
-				Neighbours: []*Neighbour{{Name: "Sha Tin"}, {Name: "Sham Shui Po"}, {Name: "Tsuen Wan"}},
-				Center:     "680 518",
-				Color:      "#a4cfa9",
-				Paths: []string{
-					"M581.1,588.2l20.2-5.8l2-2l2.8-0.5v1.5h3.5l4-4l3.5-15.4l-3.5-1.5l8.8-27.8l-5.3-13.9l-2.3-15.7l-3.3-4l-1.3-2.8l-2,1.3h-2.5l-5.6-2h-4l-1.5,2.8l-11.1,3l-13.1-2.5l-2.8,1.5l-6.8,0.8l-5.6,4l-6.8,2.5l2.3,11.6l0.3,5.1l1.3,1.8l4.3,1l0.8,4.3l2.8,1.5l0.3,9.8l8.6,1.3l5.6,5.1l-2.3,5.6l5.6,1.3l1,3.5l-6.3,15.9l0.3,4l3,7.6L581.1,588.2L581.1,588.2z",
-					"M692.6,442.5l-2.9,2.2l-11.8,3l-2.8,1.4l4.1,4.1l-1.2,5.9l-0.2,13.8l-28,2.5l-10,16.4l-14.5,7.3l-3.1,0.7l0.2,5.6l3.3-0.2l1.5,6.1l2,18.2l8.1,4l8.1,13.9l3.8,10.3l8.8-2.5l1.8,8.6l-7.8,3l4,16.2l17.7-5.1l2.8,7.6l-29,9.8l-2.3,3l1.8,2.5l12.1,6.8l8.6,1.8l0.5,0.4l0.5-3.4l-3.8-5.4l3.6-4.8l3.2-1.6l13.9-12.2l1.4-10.3l-11.6-5.9l1.2-5.3l-2.8-3.1l-5.2,1.1l-2.2-6.2l6.1,0.9l2.1-3.6l5.7-0.9l7,3.8l6.8-2.7l4.8,2.7l6.4-4.4l-3.9-2.9v-4.3l-4.5-8.8l-6.2-2.2l-2.1-20.5l4.6-5.2l1.1-4.7l3.6-1.1l1.8-3.2l-3.9-8.9l1.8-7.7l3.9-1.9l-4.3-5.6l0.5-6.9l4.3-1.1l4.1-4.6l-0.2-3.6l-9.5,0.9l-1.6-2.2l0.5-11.6H692.6z",
-				},
-			},
-			"Sai Kung": {
-				Neighbours: []*Neighbour{{Name: "Tai Po"}, {Name: "Kwun Tong"}, {Name: "Wong Tai Sin"}, {Name: "Sha Tin"}},
-				Center:     "954 561",
-				Color:      "#58825c",
-				Paths: []string{
-					"M1025.3,808.5l3.9-4.6l-2.2-2V799l2.9-2.7l7-0.6l4.7,2.7l1.3-7.8l-0.7-4.1l2.1-2.9l3.1-0.5l-0.4-6.5l-2.3-1.1l-2.9-2.7l0.8-3.2l-1.3-3.2l5.1-1.5l-0.6-2l-4.6-1.7l1.7-4.7l-2.4,0.8l-2.1,2.1l-1.8-0.9l-7.8,5.2l-1.4,4l-1.5,1.6h-3.2l-3.2-2.9l-2.4,0.3l-2.9,1.9l-0.8,3.9l-3.3,2.8l-0.1,8.1l0.9,2.4l-1.6,2.9l-0.3,3.5l4.7,0.5l6.6,3.2l4.8,3.3l0.8,1.8l-1.8,1.6l0.1,5.3L1025.3,808.5z",
-					"M1084.1,709.3l-3.3,3.8l1.5,2.5l4.7-0.1l0.5-2l-2-4.3L1084.1,709.3L1084.1,709.3z",
-					"M1157.7,733.8l1.1,10.2l2.8-0.2l2.8-9.1l1.9-2.7l-0.3-2.9l1.3-1.2l-1.4-1.4l-2.2,0.6l-0.1,3l-3.2,3.1L1157.7,733.8L1157.7,733.8z",
-					"M1162.6,758.1l1,1.9l-1,2.9l1.5,2.7l-0.1,1.5h0.9l1.4-1.2l5.6,2.1l-0.4-2.3l-2.1-2.4l1.9-1.4l-0.4-3.9l-3.6,1.4l-2.2-0.8l-1.3-1.2L1162.6,758.1L1162.6,758.1z",
-					"M1216,579.6l7.1,4.3l2.5-1.4l2.5,1.2l1.2-1.1l-4.6-3.8l-5.4-0.7l-2.5-2.5l-2.3,2.7L1216,579.6z",
-					"M1200.7,579.7l2.9-3l-3.2-8.9l5.5-3.2v-8l-3-1.2l-3.9,5.2l-3.9-2.1l-3.2,1.4l0.7,4.6l-1.1,3.4l1.6,3.2l-2,5.2l3,0.7L1200.7,579.7L1200.7,579.7z",
-					"M1160.3,617.2l2.5-5.2l3.8-2.1l1.4,1.8l5-2.7l-0.2-3.9l-0.5-7l8-5.2l-0.9-4.3l-2.7,0.5v2.5l-4.5,3.4l-3.6-3.2h-2.7l-1.2,2l-2.5,1.2l-0.5,9.3l-1.2,2.3l-2.5,1.4v4.5L1160.3,617.2L1160.3,617.2z",
-					"M1201.4,634l3.9,1.6l-0.2-2.9v-4.3l6.1-2.3l-2.1-4.3l-1.2-4.8l2-1.8l-3.6-4.1l-0.7-4.3l-5.2-3l-5.4-1.2l-4.3,2.9l1.4,4.3l0.4,4.3l2.3,1.6l-0.9,2.5l3,5.5h2.9l2.7,3.2l-0.9,4.3L1201.4,634L1201.4,634z",
-					"M1054.9,596.7l-0.7-3.9l1.6-1.8l-12.3-12l-6.4-3.6l0.5,3.9l-0.5,7.9l3.8,4.3l2.5,3.8l3.9,0.4l4.1,2.1L1054.9,596.7L1054.9,596.7z",
-					"M1098.7,587.1l5.7-1.4l1.6,0.8l3.4-1.6l4,4.4l2.8-3.2l0.4-2.8l2.9-1.4l0.8-6.4l-1.4-6.3l-4.3-4l-0.9-2l-2.3-3.3l-0.6-1.5l1.4-2.9l-3.3-2.3l-4.9-2.9l-8.3,4l-5.6-0.4l-1.1,2.8l-0.8,3l2.7,3.9l2.1,0.1l-0.8,6.6l2.1,3.7l4,1.4l-0.9,2.7l-2.4,0.8L1098.7,587.1L1098.7,587.1z",
-					"M1036.4,510l2.7-0.1l5.8,2.4l-2.4-4l-0.3-10.2l3.8-6.9l-1.6-2.1l-2.9-2.4l-3.7-3.2l-1.9-6.6l-2.5-4.9l4.7-3.9l1.9-1.8l-3.8-0.4l-2.5,3.4l-2.3,1.6v5.3l-3.4,7.7l-4.5,1.8l2.3,2.1l2.8,4.3l6.6,4.8v2.5l1.5,4.9L1036.4,510L1036.4,510z",
-					"M1060.4,467.9l2.8-5.4l-0.1-5.4l-2.3-1.8l-2.9-0.3l-2.8-2.8l-2.3,1.6l1.9,2.3l-1.9,2l0.6,5.1l3.9,1.4l1.9,3.5L1060.4,467.9L1060.4,467.9z",
-					"M1078.3,544.5l4-7.7l4.8,4l3,3.8l11.2,3.3l5.4-3.4l-0.6-3.9l-3.2-4.5v-1.4l6.2-1.6l0.5-3.2l-2.9-1.4l0.4-3l5.8,0.8l-1.4-1.8l-1.5-3v-3l3.9-2.9l-2.1-2.9l-3.7-1.4l-1.5-1.9l-4.5-3.8l-2,0.8l-1.3-1.3l-2.9,1l-2-0.8l-0.1-1.9l-2.8-1.9v-3.7l3,1.5l0.6-3.5l-3-1.8l1.3-2.4l4.3,0.4v-3.5l-3-4.2l-5.6-7.3l0.8-4.3l-1.4-2.8l-0.3-5.8l2.9-3l-4.3-2.5l-3.3,3.7l0.9,2.3l-1.6,3.4l-2.7,3.8h-3.2l-4.3,3.8l-2-0.4l4.8-7.1l3.5-3.9l-0.6-3.4l2.4-1.1l1.1-3.7l-2-1.3l-2.5,0.5l-4.5,3.3l-0.9,5.3l-3,4.5l-1,3.7l-2.3,0.4l-0.9-2.4l-1.4,2.3l1,3l-2.1,1.3l1.6,2.3l-0.6,1.6l-4.7,0.5l-1.3,1.4l0.8,3.4l1.9,2l0.4-3.7l2.7,0.1l2.4,2.8l-0.8,3.2l1.9,7.7l4.2,4.5l0.6,4.2l-3.3-0.4l-2.8-2.5l-1,1.5l1.9,3.8l2.4,2.1l-7.7,7.3l-0.1,3l0.8,2.1l-0.6,1.3l1,2.8l3.4,5.8l4.8,4.7l5.9,6.1L1078.3,544.5L1078.3,544.5z",
-					"M1204.4,297l-6.8,2.2l-1,4.6l1.8,4.3l-2.3,5.3l0.5,4.8l5.3,5.3l0.2,6.1l-0.8,4.3l4.5,7.8l-5,17.9l-1.3,7.8l-7.1,3.3l-3.3,7.3l-8.1,9.3l-11.6,0.8l-1.3-2.3v-4.3l-8.1-4.3l-6.3,0.5l-5.3-8.8l-10.2-10.9h-4.6l-4.3,7.1l-9.6,2.5l-19.1-11.2l2.8-10.6l-7.2-2.4l-1.5-3.9l-23.5-4.9l-6.6,3.8l-0.1,6.3l2.3,6.9l-4.6,5.1l1.8,4.2l-3.9,3.7l-19.8,0.1l-8.3-2.5l-11.4,6.1l-0.9,12.8l-11.2,12.6l-0.4,4.2l-8.7-1.5l-3.5,1l-8.6,9.2l-33.3,0.6l-12,11.3l0.1,9.8l3.5,3.2l-1,3.4l-16.7,1.6l1.2,5.3l-6.9,9.3l-4.4,3.2l-3.3,6.4l-19.3,13.4l-11.5,1l-9.5,19.1l1.5,10.5l-4.4,1.4l-1.8,14.1l6.1,48.8l13.3-0.8l1.6,8.9l5.7,7.5l3.2,10.1l14.8,8.3l5.3-3.4l1.3,1.6l-3.3,13.8l7.1,1.8l-1.7,9.8l-9.8,8.5l3.2,6.8l6.6,6.8l1,4.6l3.2,10.7l-3.7,2.2l-3.6-1.6l-1.5,2.2l0.4,10.6l-2.2,4l2.8,3.5l-0.5,2.8l1.4,0.2l4.3-2l2-6.1l18.9-19.4l2.5-2.3l1.8-4.5l-1.3-5.6l-3-3l-0.8-3.8l-5.1,0.5l-2-1.8l4.8-5.3l5.8-3.3l2-0.8l0.5,10.1l3,4.3l4.3-3l6.8,4.3l3.6-0.8l3.5-6.3l-1.5-7.6l1.3-0.8l2.8,3.3l4.6-0.2l1.2,3.8l-7.8,10.4l-1.5,3.8l0.8,7.1l4.3,4.6l-0.5,8.8l10.3,1v7.8l-9.1,15.2l2.5,2.5l-1,6.8l-4.8,2.5l-0.5,8.1l3.5,1.2l9.1-1.8l4-5.3l3.5,0.8l-0.2,3.3l5.6,3.8l2.5,3.8l0.2,13.9l4.6,1.2l-0.8-2.8l7.1-3.8l-2.5-5.5l1.2-2.5l11.4,3l7.6,1l2.8,6.8l5.3,3.3l4.5,1l4.3,6.1l0.8,5.3l3.3,2l1.8-3.5v-5.6l-1-1.5l3-5.3l7.6,5.3l0.8-2.2l5.1-3.3l-1.8-5.8l-3.5,3.3l-6.3,1.8l-2.3-3.5l-1.2-5.8l3.8-1.8v-2.3l-3.8-5v-9.3l4-2.3l-0.8-2.3l-5.6,2.5l-3,4.5l-4,5.1l-0.8,6.1l-4.8,1.2l-3.3-4.5v-3.5l7.3-7.8l-4,0.2l-3.8,1.8l-7.6-3.3l-3-9.1l4.8-5.1v-1.5l-4.1-0.2l-1.8-4.8l3-3l4-3.3l2.3-4l3.8,2.3l4,1l-1,3.3l4.3,3.5l6.3,0.8l6.6,1l2.2-5.6l8.8-7.6l6.8-1.2l1.3-7.6l-2.5-1.5l0.5-2.8l4.8-0.5l0.2-4.5l-6.6-8.1l-11.1-11.6l1-2.5l-2.8-3.8l-3,2.5l-6.3-1.2l-5.1,5.5l-3.5,1.3l3,2.2l-1.5,5.3l-8.1,2l-0.5-7.3l-3.8-0.5l-0.2-3.8l5.1-5.8l-2-4l1-2.5l-3.8-1l-2-4l-1.5-8.6l2.5-3.3l-0.2-2.8l-4.6,1.8l-6.8,0.5v-2.8l-4.3,0.8l-11.6,4.8l-4.5-7.1h-4.8l-5.8-3.5l2.8-5.8l-2.5-3.8l2.5-5.3l-4-4.8l-2-5.6l1.8-3.5l-2.5-3.3l-0.5-3.3h-3l-5.8-9.1l0.2-9.6l-0.2-4.1l2.5-2l-1-3l5.3-4.6l-2.5-2.2l-6.3,3l-1-2.5l0.8-2l-4.8-5.3l-4.8-0.8l-1.2-2l-8.6,0.5l-2.3,3.8h-2.5l-2.8,3.5l-2.2-1.5l0.8-5.8l6.1-2l-3.8-1.8v-3.5l6.1-3.8l0.5,2.3l5.3-0.5l-1-7.8l1.8-3.8l7.1-11.1l3-1.8l-1.8-2.5l4.1-0.8l-3.3-5.3l2.5-1.5l6.6,5.8l4.5,3l4.3,8.3l-0.8,9.1l-3.8,5.3l-5.8,2l-0.5,5.6l3.3-2.8l7.6-0.5l4.6-4l3,2.2L988,512l1.5,6.1l2,7.1l3.8,1.8l1.3-9.1l4-1v-2.5l-2-5.6l4.6-2.8l-4.1-3.3l-5-1l-3.5-6.1l-3.6-9.8l-2.2-5.8l2-3.3l7.1-12.1l-2-2.5v-2.5l-7.1,1.3l-2.8-1.5l2.5-3l13.4-10.6l1-4.3l-2.5-4.3l1.5-8.1l0.5-4.3l2-2.5v-2.8l3.8-2.5l4.3,5.1l7.1-7.6l7.8,4.3l4.6,4.8l2.2,6.8l8.1,1.8l6.3-6.8v9.3l3.3-2.5l3,5.3l0.5,3l3.8-0.8l-2.5-7.6l2-0.8l7.8,10.6l3-4l9.6,3v-1.5l-5.8-6.3l0.5-3l2.3-1v-5l17.7-5.8l6.3,0.2l2-11.6h5.8l-3,10.1l-3,6.6h-5.1l-3,3l2.2,0.8v4l6.3,0.2l-4,5.8l0.8,5.8l-3,4.5l2,2.3l2-3l1.5,0.2l6.8-4.5l1.8,3.5l-1.8,4.5l1.5,3.5l19.4,7.1l6.6-5.3l1.2,5.8l-4,8.3l1.8,10.3l5.3,1l-0.2,3.8l-9.3,0.8l-3.3,5.8l0.5,4.3l4.3,5.1l1.5,4h3.5l2-2l3.5,2.8l2.8,4l3.8,0.5l4.1,6.8l-2,2.8l-3.3-2.5l-3.3,0.8l0.5,2.8l-0.5,4.3l4,3.3v3.3l6.1,3.8l4.8-7.8h2.5l5.3-5.8l-0.2-2.5l1.5-3.3l6.6,0.8l1,8.3l4.3,5.3l0.8,3.5l-5.1-0.5l-3.5,1.8l3.5,0.8l-0.5,6.6l5.3,5.6l-1,4.8l2,7.1l2.5,4.5l3.8-1.8l2.8-3.3l-1.5-4.3l-0.8-1.8l2.5-3.5h3.3v-2.5l-4.8-4.6V538l1.3-1.8l-2-3.3l2.3-1.3l-1-2.8v-4l2.2-3l6.1,1.5l4.6,7.3l2.2-3.8l3.3,1l-1.8-3.8l-7.1-2.3l3.8-6.3l3,2.8l6.3,0.2l2.8,4h2l-0.2-4h4.3l2,3l0.5-2.5l-2.5-4l4.8-2.3l-1.5-3.8l3.3,0.2l3,1l-1-4.8h-5.3l-0.5-1.5l6.7-8.5l7.3-1.2l0.3-6.8l-8.6-6.1l-1.8-5.5l-2.9-4.3l-0.3-4.1l4.8-2.8l7.3,3.6l2.9-0.5l0.7-6.2l3.2-0.3l1.1,8.6l5.3,8.9l2.2,9.4l5.2,1.2l3.6-2.7l-1.2-3l3.2-1.1l-3.4-7.5l-5-8.4l-3-3.8l0.3-2.9l2.2-0.7l2.1,2l6.6-1.1l-1.8-4.5l2.1-3.2l-3.2-3l-0.5-14.5l-8.4-6.2l-2.3,2.5l-2.8-1.1l-3.9-4.1l-2.8-2.2l1.6-3.6l-12.1-9.1l-1.4-2.5l-5.7,6.4l-5.7,3.8l-1.2-2.8l2.9-6.6l-4.5,0.7l-3.4-7.2l-2.7-4.6l1.8-1.2l5.9,5.5h3.8l3.6-5.3l-0.2-1.8l3.6-4.6l-2.1-2.2l1.8-2.8l-2.9-3.9l2.3-2.8l1.1-2.9l9.6,5v-2.3l-5-5.3l8.6-14.5l3.9,2.1l5.2-7.3v-3.2l9.7,3.2l10-2.2l2,6.8l3.8,3.2l-1.8,2.3l8.6,5.9l0.5,4.8l5-1.4l3.9,0.9l4.6-3.6l-3-4.5l2-3.9l-1.4-1.1l-2.7,1.2l0.6-6.2l-7-4.3l-7.9-4.5l2.7-8.4l-2-3.2l-1.2-2.7l-3.2-2.5l3.8-4.3l3.8-0.7l1.4-7l2.5-0.9l2.2-3.6l-5.2-0.7l-4.7,2.7l-3.9-3.2l-0.6-4.3l-4.1,3.2l-2.7-0.7l-0.3-5.7l-3.9,2.3l-9.5,1.2l-4.6,2.3l-1.8,2.7l-4.8,0.7l-2.8-1.1l-5.4-2.5l-1.6-2.5l-5.7,0.5l-5.3,4.3l-2-1.2l-3.8-1.6l0.9-5.7l-4.4-6.2L1204.4,297z",
-				},
-			},
-			"North": {
-				Neighbours: []*Neighbour{{Name: "Yuen Long"}, {Name: "Tai Po"}},
-				Center:     "785 94",
-				Color:      "#649668",
-				Paths: []string{
-					"M1022.1,73.1l7.5-3.6l10.5-0.2l4.8-2.7l2,1.1l2.5-3.6l6.6,1.6l-8.8-9.5l-3.2-6.6h-3.2l-2.7-7.5l3.6-5.4l11.4-0.5l11.2,7.9l0.2-3.8l3-2.1l2.9,1.2l0.9-5l8.2,3.4l7.3-4.6l-7.7-1.4l-13.8-1.6l-4.1,2.5h-6.1l-6.1-2.5l-10.4,0.2l-2.9-4.8l2-3.9l-4.6-3.6l-7,7.7l8.8,7.3l-0.9,4.5l-1.8,3.9l-3.4,1.8l7,7.9l5.7,7.5V61l-5.7,1.4l-2.5-3l-3.9,2.1l-1.6-1.1l-2,2.1l0.4,4.3l-3.6,3L1022.1,73.1L1022.1,73.1z",
-					"M1073.7,85.4l4.1,0.9l12.3,9.8l3.9-1.1l2.7,3l2.5-4.5l-2.3-8.6l0.4-6.1l3.8-4.3l-1.4-4.5l-2.7-2.7l1.2-3l-3.8,0.7l0.5,8.2l2.1,2.1l-3.4,7.5l-3.8,1.4l-2.1,2l-3.8-1.2V81l-3.9-3.6l-0.2-4.5l-4.1-0.5l-0.7-2l-3.4,3.6l0.9,8.2l-0.5,1.6L1073.7,85.4L1073.7,85.4z",
-					"M1048.8,144l8.1-2.4l6.2-7.6l-6.4-3.5l-0.3-2.5l-2.7-3.3h-2l1.4,3.4l-2.1,1l-3-1.4l-0.6-2.1l-1.5,0.5l-4,1.3l6.8,6.2l-0.1,2.5l-3.3,3.8l2.8,0.6L1048.8,144L1048.8,144z",
-					"M1063.2,133.4l4.2-1l8,3.2l6.3-6.4l9.2-2.5l2.7-7.3l5.4-3l-1.4-4.5l-4.2,0.5l-2.7,2.1l-0.3-9.5l-14.1-11.4l-3.8,1.4l3.7,2.7l-0.9,1.9l-1.3,0.8l0.4,6.7l2.3,2.4L1070,121l-0.4,3.3l-2.3,2l-3,1l0.5,2.8L1063.2,133.4L1063.2,133.4z",
-					"M735.2,0l-3.9,2.4l-3,2.3l-3.5,10.9l-0.4,1.8l-9.2-0.4l-2.2,9l-1.8,2.9l-1,3.2l2.8,4.4l-0.1,0.1l0,0.1l-6.2,5.3l0.3,5l-0.4,0.1l0,0.2l-5.1,1.5l-4.8,1.6l-10.8-6.8l-2.3,0.2l-4.9,0.9l-13.2,5.6l-4.7,4.2l-4.5,0.9l-1.6-1.8l-7.7,4.5l1.8,5L645,65l-1.1,2.5l-3.8,0.4l-5.9-0.4l-0.9,6.6l-6.1,4.6l-5.2,0.7l-4.3-2.7l-1.1-11.6l-2-1.1l-2,3l-4.4,1.4l-2.3,5.3l-4.6,1.1l-1.1-6.9l-11.6,1.2l-8-6.4l-8.9,0.2l-3.2,6.6l-15.2,6.6l-1.1,3.4l12.7,10v6.4l-6.7,8.7l0.6,1.4l3.1-1.4l4.4-6l3.2-1.3l5.7,5.6l-2.1,12.3l2.8,10.1l2.8,3.1l-3.3,3.6l-4.7,0.6l-0.1,8l2.8,2.5l1.8,12l-3.1,4.2l2.5,9.6l3.4,4.3l-2.8,18.6l-5.2,9.8l0.8,37l19.4-1.5l13.2-7.5l9.7,0.7l9.4-4.8l7.4,0.7l4.2,6.3l6.5,1l3.1,6.1l13.8-0.9l3.8-4.2l11.6-2.2l8.4-5.3l6-6.5l5.4-1.5l2.7-2.1l8.5-3l3.8-10.2l4.6-1.3l4.8,1.2l-0.4-6.7l-5.4-10.6l4.8-5.3l14.3,0.7l11.3-2.6l-2.1,7.3l2.3,2.6l10.5-2.1l2.7,6.3l3.2-0.8l0.4-5.8l7.6-12.6l11.4-8.2l9-1.3l14.3,1.3l3.5,1.9l4-1.2l5.2,4.7h6.2l9.8,2.9l10.5,5.5l7.1-2.2h4.8l8.4,2.8l9.5,0.5l4.8,5.7l2.7-0.5l2.6-9.2l7.7-3.6l9-13.5l5.1-1.8l3.8-4.5l5.9-1.7v-6.3l6.5-2.8l1.3,5.4l5.8,4.2l8.5,13.1l7.6,5.8l21.7-1l4.7,0.6l5.9-2.1l0.1-4.6l5.3,1.1l5.3-2.6l6.2,1.1l12.6-3.2l11.7,0.8l23.6,14.8l7.5,1.1l8.1,5.3l22.7-15.3l8.7-3.4l1.4-4.8l15.2-10.1l8.8-1l10.9-9l2.9-5.2l6-2.8l-2.4-3.4l-2.8-0.2l-12.4,9.3l-3,3.5l-7.8,0.8l-7.1,4.8h-9.6l-7.6,3l-8.3,2.5l-4,1.3l-3.3,4.5l0.2,3.5l-2.5,2l-5.8-9.8h-8.3l-4.5-3.8l1.8-2.8l-2.8-1l-2,2.5l-3.3-2.8l3.8-7.1l-4.8,2l-2.5-4.6l-1.3,5.3l2.8,2l-0.2,4.8l-2.8,0.5l-3.5-5.3l-5.8,1v-4l2.8-4.6l-3.5,1.5l-5.8,0.8l-5.8-3l1.5-2l6.8-0.8l-2-2.5l-2,1.8h-7.8l-1.8,3l-6.1-1.3l-1.8,4.3l-4.8-0.5l-5.3,4.8l-1.5-2l3.5-7.3l4.1-0.5l1-6.3l-2-1.5l-3.5,2.2l0.5-6.3l2.5-3l-2-2l2.2-2.3l5.3,2h3l4.3,7.8l2.5-6.3l-1.2-1.2l1-5.1l5.6-1l-3-4.8l6.1-1.2l5.1-4.1l2.2-6.1l-2,0.3l-3.3,4.3l-3.6-1.3l-0.8,2l-3.5,2.5l0.2,1.5l-2.5,1l-2.2-2.3l-3.3-2l3.5-4.8l1.8-5.1l4.1-2.3l-0.5-1.5h-2.5l-9.3,3.3l2,1.5l-6.1,2.8l-2,0.8h-4.6L981,92l-3.3-3.8l-2.8,2.8l-9.3,3.5l-1.5-7.1l2.5-6.3l1.8-4l2.2-3.8l6.3-2.2l1.8-2.8l-2.5-2.8h-4.3l-3,1.5l-0.2,3.6l-2.8,1.8l-2-1.5v-5.3l2-2.5l2.5-4.3l-1.8-3.5h-3.8l-2,3.8l-6.3,2.5l-1.8-1.8l-0.2-4l5.3-5.1l-3-2.8l0.5-1.5l-3-1.8l2.8-5.3l4.8-3h-4.1l-2.8-3.5l-3,0.5l1.2,6.6l-2.8,4.3l-3.8-0.2l-1.3-2.5l2.5-3.8l-4.3-0.8l-2,2.5l1.8,1.8l-1,1.2l-6.8,1l-3.5-4.8l1.3-4.3l-4.1-2.3l0.2,6.8l-3,2.3l-1.8-2.3v-4l-3.3,2.5l-0.8,9.8l-4.8,6.3l-2.5,11.4l-11.9,11.6l-4-1l-4.3-4.3v8.8l-3.8,2.5l-8.1,2.5l-6.1-4.8v5.5h-2.5l-1.5,4.6l-9.9,4.3l1-6.1l-3.5,2.5h-8.8l-1.3,3.8h-3l-2.2-7.6l2.8-4l-1.5-3.6l2-4.3l-1.8-5.6l3.3-5.3l5.6,3l6.1-5.1l1.2-9.1l7.6-5.6l-0.5-4.8h2.8l2.5,2.8l7.6-2.3l3.3,3l2-1.8l-0.6-3.2l0.8-1.5v-0.4l1.5-3.6l-6.7-7.3l-0.9-0.8l-0.5-0.2l-3.5-0.5l-1.4-1.5l-0.9-0.4l-4.5-5.5l-3.1-3.3l-3.1,0.2l-8,0.5l-0.7-0.2l-1.4,0.1l-15.8-4l-2.3,0.3l-3.4,1.1l-4.1-3.5l-11.5,3.1l-2.9,1.6l-3.9,0.2l-5.2,1.4l-6.6-0.8l-11.1-1.2l-0.8,0l-4.2,1l-1.7-1.2l-1.6-0.1l-0.7-1.6l-0.3-0.2l-0.9-2.6l-2.7-6l-1.9-1L766.2,4l-1.9,1l-5.7-0.7L757,4.4l-1.8-1.2l-4.3-2.5L744.2,4l-3,1.8L735.2,0z",
-				},
-			},
-			"Tsuen Wan": {
-				Neighbours: []*Neighbour{{Name: "Yuen Long"}, {Name: "Tai Po"}, {Name: "Sha Tin"}, {Name: "Kwai Tsing"}, {Name: "Lantau Island"}, {Name: "Tuen Mun"}},
-				Center:     "608 450",
-				Color:      "#7dba84",
-				Paths: []string{
-					"M680.7,360.3l-15.4,0.8l-12.9,3.3l-6.4,8.6l-2.1,6.7l-11-2.8l-9.3,6.2l-1.2,3.8l-14.5,3.5l-4.3-5l-8.7,0.2l-13,15l-6.4,4l-6.1-3.3l-8.5-2.4l-13.6,12.5l-10.5,0.2l-2.9,5.8l1.5,2.9l-9.8,0.2l-12,6.8l-1.5,13.3l-18.2-0.2l-4.9,4.2l-8-3.4l-7.3-1.9l-2.7,1.5l-0.6,6.8l2.3,5.7l-4.4,2.9l-3.4-2.8l-11-3.7l-5.5,4.8l0.4,2.4l-2.3,2.4l-3.3-0.2l-3.7,0.4l-4,5.7l1.1,2.4l4.7,0.9l1.3,7.9l7.6,4.3l4.8-1.8l1.8,1.5l-0.8,4.1l-5.6,3.5l-6.6,1.2l-2.3,8.1l-12.9,8.3l-0.5,9.5l2.9,0.2l20.2-4.3l3-3.8l3-1.2l8.8-3.8l7.6-2.8l2.3,1.8l8.8-3.5l5-1l7.8-7.1l1.5-1l2.5-4l3,5.3l3.3-6l2.3,0.8l1.2,9.6l15.9,0.2l3.8-5.6l4.5-1.5l4-4.3l6.6,1.5l2.5-4.3l17.9,6.1l9.8-2l14.7-1.5l12.4-9.8l22,17.2l-3,8.6l1,0.2l15.9-6.3L649,476l28.9-4l-1.2-9.9l2.8-6l-3.3-5.4l-0.4-2.4l12.2-2.7l4.9-3.3l3.6,2l-1.9,10.6l6.4,1.4l11-7.2l4.2-0.5l7.6-3.9l1.7-5.9l6.9-1.9l4.2-9.1l10.8-10.5l-1.2-11.4l2.4-4.8l0.8-10.6l-5.4-7.8l4.2-11.5l-18.8,0.2l-4.3,3l-6.3-2.7l-7.1,1l-12.1,0.2L698,365l-4-2.2l-4.6,1.6L680.7,360.3z",
-					"M503.8,547.4l13-8l-2.4-3.3l-1-3.8H508l-2.3-6.4l5.9-2.9l-1.8-3l4.7-4.5l-7.4,2.7l-7.2-2.8l-3.5,3l2.8,4.9l-1.3,4.2l-6.1,4l6.7,2.1l-1.9,8.2l3.8,1.1L503.8,547.4z",
-					"M357.7,615.7l23.9,0.2l3.9-3.4l4.6,3.4H447l1.8-4.1l-3.9-9.8l-1.2-8.4l-1.6-2.7l1.6-1.8l5.9-0.7l0.5,1.8l5.7,0.5l7.5,8.9l-0.7,3.2l3.2,2.3l2.9,6.1l3.4-0.2l1.6-3.8l5-1.1l4.1,0.9l4.5-3.4l2.3-4.8l1.6-13l4.8-5.7l-3.6-0.7l-0.7-7.1l1.2-0.7l-1.8-2.7l4.8-2.9h2.1l-0.9-2.7l0.5-2.3l-5.4-4.5l0.7-2.7l-3.9-4.6l-0.9-2.7l-2.1-0.2l-4.3-6.8l-9.8-0.5l-1.6-2.5l-2.1,5.2l2.1,6.2l-2,3.4l-6.6,2l-2.5-0.9l-2,1.4l-4.1,1.4l-0.5,3l-6.8,5.9l-3.4,1.4l-6.8,7.3l-4.6,1.2l-1.8,4.6l-7,7.3l-5,2.9l-2.7-1.6l1.6-4.5l2.3-7.5l-3.9-0.2l-2.3-2.9l-3.6,4.1l-3.6,5.2l-10.5,2.3l-1.2,1.6l0.5,5.2l-4.5,2.5l-1.8-0.4l-0.4-2.9l-4.5,5.7l-5,2.9l-4.8,4.3l-7.9,0.7l-1.6,2.5l-2.1-0.5l-2.7,2l1.6,1.2l-0.9,2.3L357.7,615.7L357.7,615.7z",
-				},
-			},
-			"Wan Chai": {
-				Neighbours: []*Neighbour{{Name: "Yau Tsim Mong"}, {Name: "Eastern"}, {Name: "Southern"}, {Name: "Central & Western"}},
-				Center:     "789 728",
-				Color:      "#014e82",
-				Paths: []string{
-					"M760.3,689.6l-1.9,3.8l-1,6.1l-3.7,1.1l-3.7,0.1l0.2,2.2l2.5,1l-0.1,1.2l-2.5,0.1l0.4,1.2l2,3.9l-2,6.6l-4.6,7.6l1,17.2l-5.7,11.5l26.4,11.2l5.8-5.3l10.8,4.4l7.6,2.2l12-4.4l15.2-3.5l0.6-18.8l8.2-4.8l11.1-11.9l-11.5-12.9l-3,5.7l-4.9,3.4l-7.6-7.8l-5.7-12.2l-6.7,1.7l-4.4,3.4l-3-6.4l-1.5,0.2l-0.2,0.5l-4,1.3l-4.1-0.8l-4-1.1h-3.8l-3.8,1.2l-3.3-0.2l-1.3-0.8l0.1-3.5l0.8-4H760.3z",
-				},
-			},
-			"Tai Po": {
-				Neighbours: []*Neighbour{{Name: "North"}, {Name: "Sai Kung"}, {Name: "Sha Tin"}, {Name: "Tsuen Wan"}, {Name: "Yuen Long"}},
-				Center:     "739 310",
-				Color:      "#b1e2b4",
-				Paths: []string{
-					"M1178.1,157.6l3.5,3.8v6.6l3.3-3.5l4,1l0.3-6.3l4.5-8.6l-4.3-2.3h-5.1l-3,2.8l0.5,4.3L1178.1,157.6z",
-					"M1187.3,245.2h3.4l6.1-8.3l-1.3-7.3l5.4-2.1l2-8l-9.5-7.8l0.3-6.7l2.9-2.4l-0.4-5.3l-6.1-2.1l-2-2.4l-6.1,1.8l-2.3,6.7l-5.9,2.8v3.7l7.2,3.9l0.4,8.8l2.4,4.4l-0.4,2.5l4.8-4l2.5,3.2l-4.7,4.7l-0.6,5.7l-1.4,4.4L1187.3,245.2L1187.3,245.2z",
-					"M1130.5,130.6l-6.5,3.3l-2.5,5l-12.3,8.8l-8.9,1.8l-14.3,10.5l-1.6,3.6l-17,8.9l-13.8,9.8l-9.1-6.1l-6.6-0.9l-22.8-13.9l-15-1.2l-8.4,4.1l-8.2-2l-4.8,3.4l-5.2-1.8l0.2,4.5l-6.6,2.5l-4.3-0.6l-22.7,1.2L927,156.8l-1.8-5.5l-4.8-1.8l-2-6.6l-7.7,3.4l0.2,6.6l-5.5,0.9l-3.8,5l-5.7,1.4l-8.4,13.4l-7.5,3.4l-3.1,10.2l-7.5-5.9l-23.4-4.1l-5.4,2.3l-12.3-6.4l-15.3-2.1l-5.2-4.1l-3.6,1.2l-7.5-2.8l-19.5,0.5l-12.2,10l-6.6,12.3l-0.9,5.2l-4.1-0.9l-1.6-5.2l-11.9,1.4l0.7-9.8l-10.9,3.2l-13.9-0.4l-4.6,5.7l5.5,11.8l0.2,4.7l-7.8-0.9l-4.1,7.2l-2.3,5.2l-7.8,1.6l-2.5,3.2l-5.7,1.2l-8.9,9.1l-8.2,3.4l-4.4-0.2l-7,3.4l-4.8,13.2l-9.3,10.7l-1.6,4.8l-5.7,5.9l-15.5,20.2l-0.4,5l7.2,17.3l3.2,5l1.2,11.4l9.5,8.2l1.6,6.6l6.4,6.6l0.7,12.8l12.3-3.6l16.4-1.2l9.5,4.5l5.9-2.2l3.6,2.7l1.6,8.6l5.7-1.4l5.3,1.1l7-1.6l7.5,3.4l3.2-3.9l4.8,1.1l26.8-1.4l1.2-5.7l26.1-0.9l1.4-5.7l29.3-0.4l3.2-9.8l2.3-10h2.5l2.1,7.5l3-7.5l7,0.7l1.2-2.9l-11.8-8.2l-3.6-10l-17.9-15l-7.1,1.1l1.4,5.7l-4.6-0.7l-3.9,7.5l-0.7-8.9l2.5-3.2l-21.4-13.6l3.2-2.9h-4.3l-0.7-4.3l-8.2-3.6l-1.4,7.1l-2.9-3.2l-0.7-5.3l-6.8-0.3l6.8-3.2l5.7,0.3l6.1,3.6h5.7l3.9-4.6l16.4-1.1l10,3.2l7.8-4.3l3.9-12.1l10.4-6.4l6.1,3.9l4.3,4.3h13.2l3.2,3.9l-2.5,6.1l-6.1,6.1l6.1,3.2l4.3-0.3l4.7,3.9l-0.8-5.1l3-1.5l6.3,0.8l-4-4.3l-8.8-1.5l0.5-7.3l-4.5-6.3l-7.3-1.5l-6-8.8l2-2l2-15.9l12.1-10.1l1.5,0.5l5.6,8.8l7.6,3l10.4-8.6l10.1-0.5l3.8-0.5l5.1,8.3l-2,1.5l5.6,2.5l28.5,30.8l-2,10.1l8.8-7.3l15.2-1.5l1.3-2.8l4.5-1.8l4.6-11.6l12.9-10.8l-2.8-9.8l7.1-2.5l0.5,3.8l5.6-1.8l1.5-5l9.8-1.3l8.1,0.2l21.5-10.3l7.6-2.5l2.5-5.8l15.4-2.8l15.4-11.4l-6.6-1.3l2.3-4.3l4-0.2l2-4.3h3.8l5.1-5.1l-1.5,7.3l5.6-3.5l9.6-7.8l2.8-7.1l8.6-7.6l2.8-5.8l5.8-1.8l3.8-5.3l4.8-1.3l2-2.8l4-1.8l5.1-5.8L1130.5,130.6z",
-					"M1098.9,204.5l-2,1.8l0.7,4.7l-2.3,2.7l-3.6-0.5l-2-1.1l-5.5,4.1l-1.2,5.7l-4.8,3.9l-7.3,2.2l-7.3,10.9l-2.5,9.5l-3.2-0.2l2.8,9.7l-2.1,8.2l-7.9,4.3v-4.7l-11.4,0.4l-6.4-6.8l1.4,10l-1.4,5l0.3,3.2l-5.3,5l-1.1-6.8l-7.5-5l-2.5,6.1l-8.9,4.6l1.4,4.3l-7.2,5.4l7.2,5l2.5-2.5l5,5l-8.2,5.7l6.1,15.7l0.7,5.4l13.2,8.2l-4.3,5.3l-3.2,4.3l-24.3,15.7l-1.4,8.9l3.2,12.8l-3.2,2.9l-4.7-10.7l-9.6-12.2l-5-6.1l1.1-3.9l8.6-4.6l-2.8-17.9l-3.6-6.8h-6.4h-5.7l-3.2,3.2h-8.6l-3.9,3.9l-2.5-6.4l-4.6-3.2l-11.9,6.2l0.3,4l-3.3,3.8l1.8,1.8l3.3,2.5l-0.2,6.1l1,4.1l-3.3,6.8l0.5,6.6l-0.5,9.3l5.8,1.5l3.5,7.3l-1.5,7.3l4.3,6.3l-1.2,3.5l2.3,4l-3,7.1v10.8l11.8-10.3l33.6-0.5l10.6-11.1l10.3,1.8l0.5-4.3l11.9-14.6v-9.8l6.1-5.3l8.3-1.5l8.1,3.3l6.3-1.8l8.1,1.5l6.3-4l-0.8-4.5l3.3-5.8l-1.5-10.6l6.6-5.6l24,5.1l2.5,4l6.3,2.8l-3,3.5l0.5,6.3l10.1,7.8l10.9,4l7.8-4l3.3-5.8l4.3-0.8l4.3,6.1l8.1,8.1l4.8,7.3l4-1.8l9.3,4l-0.2,6.3l8.8,0.8l7.6-5.1l9.1-13.6l6.5-4.3l0.7-10l5.3-12.8l-5.5-8.4l1.4-9.3l-6.8-7l2.9-10.5l-1.4-7.1l7.5-2.7l-0.3-1.2l-3.4-7.5l0.4-3.8l3.6-0.3l3.6-4.5l1.8-10.7l-7.1-8.8l-1.1-3.2h-6.2l-4.4-1.6l-1.6,12.3l3.6,5.5l-3.2,3.6l-4.5-3.2l-3.9,3.9l-1.1,3l-4.3,0.9l-1.2-3l-3.6,1.8l4.3,7.3l-1.9,2.2l3.2,0.5l1.8-2.2l2.3,1.2l-3.2,5.9l1.6,8.8l-2.3,3.4l-1.4,8.4l0.7,3.9l-2,6.8l1.2,3.9l-2.1,3l0.2,1.8l3.6-1.4l-0.4,3l2.3,2.2v3.4l-5.5,1.2l5.7,3.8l-0.3,2.8l-8.9-0.9l-2.1-1.1l-3.9,2.9l-2.5-3.2l-5.2-0.9l0.2-3.2l2.7-3.9l6.1-2v-2.7l-2.2-1.1l-0.2-9.8l-5.7-9.1l0.3-3l1.2-1.8l-1.4-2.9l-2.2-1.8l-5.5,5.5l-2.2,5.4l-1.2,9.6l-4.5,0.2l-5.2-0.7l4.6,5.2h4.8l-0.9,3.2l-7.2,0.3l-3.9-0.5l-3.6,2.3l-5-3.6l1.4-2.3l5-0.7l1.8-4.7l0.9-8.8l-10-10.9l4.6-4.7l4.3,0.5l2.3,1.2l7.3-6.6v-9.7l-6.1-0.9l-2-2.7l3.6-3.8l-0.7-3.4l-4.4-6.2l1.4-3.9l3.4-11.4l-2.5-4.5l0.3-3.4h4.5l5.2,1.6l4.8,3.8l5.5-2.5v-5.7l-3.4-6.2l0.9-2.5l-5-8.2l2.2-3.6l-4.1-6.2l-0.9-10l-2.7,1.6l-0.7,3.9l-2,4.5l-2.5,0.2v2l2.2,4.1v7.8l-2.2,6.4l-4.6,3l1.6,2.7l-0.3,5.5l-2.3,1.6l-3.1-2.9l-1.6,2.9l-3.2-0.6l-1.6-4.1l-2.5,2.3l-1.4,3h-3.9l-4.8,3.8l0.5-4.1l-1.1-2.3l0.9-4.1l2.9-4.1l0.7-6.8l1.8-2.3l0.7-4.4l3.2,0.5l0.9-5.2l-3.6-1.8l-2.5,2l1.4,4.1l-2.7,0.9l-8.4-0.4l-3.2-5.3l0.7-6.8l1.4-2.2L1098.9,204.5z",
-					"M878.3,279.3l1.7,2.4l10.7-3.8l6.4-7.8l2.1-6.6l-7-3.3l-7.7,6.8L878.3,279.3L878.3,279.3z",
-				},
-			},
-		},
-		Regions: map[string]*Region{
-			"New Territories": {
-				Territs: []string{"Tuen Mun", "Yuen Long", "North", "Tai Po", "Tsuen Wan", "Sha Tin", "Sai Kung", "Kwai Tsing"},
-				Color:   "#8fd694",
-				Bonus:   7,
-			},
-			"Kowloon": {
-				Territs: []string{"Kowloon City", "Kwun Tong", "Sham Shui Po", "Wong Tai Sin", "Yau Tsim Mong"},
-				Color:   "#f24c00",
-				Bonus:   8,
-			},
-			"Hong Kong Island": {
-				Territs: []string{"Central & Western", "Eastern", "Southern", "Wan Chai"},
-				Color:   "#016fb9",
-				Bonus:   4,
-			},
-			"Islands": {
-				Territs: []string{"Lantau Island", "Lamma Island", "Po Toi"},
-				Color:   "#ffc759",
-				Bonus:   2,
-			},
-		},
+	game.lock.Lock()
+	defer game.lock.Unlock()
+	actions := []*Action{
+		{JoinGame: &JoinGameAction{Player: "wahtever"}},
+		{JoinGame: &JoinGameAction{Player: "hawflakes"}},
+		{StartGame: &StartGameAction{Player: "wahtever"}},
+	}
+	for _, action := range actions {
+		if _, err := game.applyActionLocked(action); err != nil {
+			return nil, err
+		}
 	}
-	return m
+	return game, nil
 }
@@ -3,19 +3,28 @@ interface ControlPanelProps {
     activePlayer: string,
     thisPlayer: string,
     territs: Map<string, TerritoryData>,
+    onResign?: () => void,
+    onKick?: (player: string) => void,
 }
 
 function ControlPanel(props: ControlPanelProps) {
     const rows = props.players.map(player => {
         let playerName = <span style={{color: player.color}}>{player.name}</span>
+        if (player.team) {
+            playerName = <span>{playerName} [{player.team}]</span>;
+        }
         if (props.thisPlayer == player.name) {
             playerName = <b>{playerName}</b>
         }
         if (props.activePlayer == player.name) {
             playerName = <span>--&gt; {playerName} &lt;--</span>;
         }
-        if (player.eliminated) {
+        if (player.resigned) {
+            playerName = <span><s>{playerName}</s> (resigned)</span>;
+        } else if (player.eliminated) {
             playerName = <s>{playerName}</s>;
+        } else if (player.abandoned) {
+            playerName = <span>{playerName} (away)</span>;
         }
         
         return (
@@ -25,6 +34,8 @@ function ControlPanel(props: ControlPanelProps) {
                 <td>{player.territories}</td>
                 <td>{player.troops}</td>
                 <td>{player.reinforcements}</td>
+                {props.onKick && player.name != props.thisPlayer && !player.eliminated ?
+                    <td><button onClick={() => props.onKick!(player.name)}>Kick</button></td> : null}
             </tr>
         );
     });
@@ -53,22 +64,42 @@ function ControlPanel(props: ControlPanelProps) {
             </table>
             <h3>Spoils</h3>
             <p>{spoils}</p>
+            {props.onResign ? <button onClick={props.onResign}>Resign</button> : null}
         </React.Fragment>
     );
 }
 
+interface TurnClockProps {
+    deadline: string,
+}
+
+function TurnClock(props: TurnClockProps) {
+    const [now, setNow] = React.useState(() => Date.now());
+    React.useEffect(() => {
+        const interval = setInterval(() => setNow(Date.now()), 1000);
+        return () => clearInterval(interval);
+    }, []);
+    const seconds = Math.max(0, Math.ceil((Date.parse(props.deadline) - now) / 1000));
+    return <p>Turn ends in <b>{Math.floor(seconds / 60)}:{String(seconds % 60).padStart(2, '0')}</b></p>;
+}
+
 interface TerritoryDetailsProps {
     name: string,
     owner: string,
-    troops: number,
+    troops: number | null,
     neighbours: Neighbour[],
 }
 
+// NEUTRAL_OWNER owns territories that belong to no player, while territories
+// nobody has drafted yet have no owner at all.
+const NEUTRAL_OWNER = '(neutral)';
+
 function TerritoryDetails(props: TerritoryDetailsProps) {
+    const owner = props.owner == NEUTRAL_OWNER ? 'neutral' : props.owner || 'nobody';
     return (
         <div>
             <p><b>{props.name}</b></p>
-            <p>Owned by <u>{props.owner}</u> with <b>{props.troops}</b> troops</p>
+            <p>Owned by <u>{owner}</u> with <b>{props.troops ?? '?'}</b> troops</p>
             <p>Neighbours: {props.neighbours.map(n => n.name).join(', ')}</p>
         </div>
     );
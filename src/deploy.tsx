@@ -1,6 +1,9 @@
 interface LobbyPanelProps {
+    team?: string,
     onStartGame?: () => void,
     onJoinGame?: () => void,
+    onAddBot?: (kind: string) => void,
+    onChooseTeam?: (team: string) => void,
 }
 
 function LobbyPanel(props: LobbyPanelProps) {
@@ -14,16 +17,51 @@ function LobbyPanel(props: LobbyPanelProps) {
         <div className="phase-panel" style={{ backgroundColor: 'grey' }}>
             <h1>LOBBY</h1>
             <div style={{ flexGrow: 1, display: 'flex', justifyContent: 'flex-end'}}>
+                {props.onChooseTeam ? <label>Team <input type="text" defaultValue={props.team ?? ''} onBlur={e => props.onChooseTeam!(e.target.value)} /></label> : null}
+                {props.onAddBot ? <button onClick={() => props.onAddBot!('random')}>Add Random Bot</button> : null}
+                {props.onAddBot ? <button onClick={() => props.onAddBot!('heuristic')}>Add Heuristic Bot</button> : null}
                 {button}
             </div>
         </div>
     );
 }
 
+interface DraftPanelProps {
+    pool: number,
+    // Set in bid drafts. Without an auction, the bid opens one for the
+    // selected territory.
+    auction?: Auction,
+    selection?: string,
+    onBid?: (troops: number) => void,
+}
+
+function DraftPanel(props: DraftPanelProps) {
+    const minimum = props.auction ? props.auction.bid + 1 : 1;
+    const [bid, setBid] = React.useState<number>(minimum);
+    let status = <span>Pick a territory to claim</span>;
+    if (props.auction) {
+        status = <span>{props.auction.territory}: {props.auction.bid} troops from {props.auction.bidder}</span>;
+    } else if (props.onBid) {
+        status = <span>{props.selection ? `Open bidding for ${props.selection}` : 'Pick a territory to bid for'}</span>;
+    }
+    return (
+        <div className="phase-panel" style={{ backgroundColor: 'blue' }}>
+            <h1>DRAFT</h1>
+            <div style={{ flexGrow: 1, display: 'flex', justifyContent: 'flex-end' }}>
+                <p style={{ color: 'white', paddingRight: '4px' }}>{status} (pool: {props.pool})</p>
+                {props.onBid ? <input type="number" min={minimum} max={props.pool} value={Math.max(bid, minimum)} onChange={e => setBid(e.target.valueAsNumber)} /> : null}
+                {props.onBid ? <button disabled={!props.auction && !props.selection} onClick={() => props.onBid!(Math.max(bid, minimum))}>Bid</button> : null}
+                {props.onBid && props.auction ? <button onClick={() => props.onBid!(0)}>Pass</button> : null}
+            </div>
+        </div>
+    );
+}
+
 interface SpoilsPanelProps {
     thisPlayer: string,
     spoils: Spoil[],
     mandatory: boolean,
+    nextBonus?: number,
     territs: Map<string, TerritoryData>,
     onPlaySpoils: (spoils: string[]) => void,
 }
@@ -68,6 +106,7 @@ function SpoilsPanel(props: SpoilsPanelProps) {
     return (
         <div className="phase-panel" style={{ backgroundColor: 'blue' }}>
             <h1>PLAY SPOILS</h1>
+            {props.nextBonus ? <span>Next set: {props.nextBonus} troops</span> : null}
             <div style={{ flexGrow: 1, display: 'flex', backgroundColor: 'white' }}>
                 {spoils}
             </div>
@@ -96,6 +135,8 @@ function DeployPanel(props: DeployPanelProps) {
 }
 
 interface AttackPanelProps {
+    blitz: boolean,
+    onBlitzChange: (blitz: boolean) => void,
     onFinish: () => void,
 }
 
@@ -104,6 +145,7 @@ function AttackPanel(props: AttackPanelProps) {
         <div className="phase-panel" style={{ backgroundColor: 'red' }}>
             <h1 className="dark">ATTACK</h1>
             <div style={{ flexGrow: 1, display: 'flex', justifyContent: 'flex-end' }}>
+                <label><input type="checkbox" checked={props.blitz} onChange={e => props.onBlitzChange(e.target.checked)} /> Blitz</label>
                 <button onClick={props.onFinish}>End attack</button>
             </div>
         </div>
@@ -1,6 +1,7 @@
 interface TerritoryData {
     owner: string,
     troops: number,
+    hidden?: boolean,
 };
 
 interface TerritoryImmutableProps {
@@ -29,6 +30,11 @@ interface Player {
     troops: number,
     territories: number,
     spoils: Spoil[],
+    abandoned?: boolean,
+    resigned?: boolean,
+    bot?: string,
+    team?: string,
+    pool?: number,
 }
 
 type Spoil = {
@@ -38,6 +44,23 @@ type Spoil = {
 
 type LobbyPhase = {};
 
+type DraftPhase = {
+    mode: string,
+    auction?: Auction,
+};
+
+type Auction = {
+    territory: string,
+    bid: number,
+    bidder: string,
+    nominator: string,
+    passed: string[],
+};
+
+type PlacementPhase = {
+    troops: number,
+};
+
 type SpoilsPhase = {
     mandatory: boolean,
 };
@@ -59,10 +82,14 @@ type ReinforcePhase = {}
 
 type GameOverPhase = {
     winner: string,
+    team?: string,
+    winners?: string[],
 }
 
 type Phase = {
     lobby?: LobbyPhase,
+    draft?: DraftPhase,
+    placement?: PlacementPhase,
     spoils?: SpoilsPhase,
     deploy?: DeployPhase,
     attack?: AttackPhase,
@@ -164,6 +191,7 @@ interface Hover {
 }
 
 type GameState = {
+    seq: number,
     phase: Phase,
 	active_player: string,
     players: Player[],
@@ -171,9 +199,17 @@ type GameState = {
 	territs: Map<string, TerritoryData>,
     territsImmut: Map<string, TerritoryImmutableProps>,
     regions: Map<string, Region>,
+    next_spoils_bonus?: number,
+    turn_deadline?: string,
+    settings?: GameSettings,
+}
+
+type GameSettings = {
+    teams?: boolean,
 }
 
 type GameEvent = {
+    seq: number,
     player_joined?: Player,
     deploy?: DeployEvent,
     attack?: AttackEvent,
@@ -181,20 +217,42 @@ type GameEvent = {
     reinforce?: MoveEvent,
     phase_changed?: PhaseChangedEvent,
     stats_changed?: StatsChangedEvent,
+    timeout?: TimeoutEvent,
+    resigned?: ResignedEvent,
+    team_chosen?: TeamRequest,
+    claimed?: ClaimedEvent,
+    bid?: BidRequest,
+    placed?: DeployEvent,
     snapshot?: GameState,
 }
 
 type DeployEvent = DeployRequest;
+type ClaimedEvent = {
+    player: string,
+    territory: string,
+    troops: number,
+}
 type AttackEvent = {
     player: string,
     defender: string,
     from: string,
     to: string,
-    defender_rolls: number[],
-    attacker_rolls: number[],
+    attacker_dice: number[],
+    defender_dice: number[],
     attacker_losses: number,
     defender_losses: number,
     conquered: boolean,
+    // Blitz attacks have no dice of their own, only those of each roll.
+    blitz?: boolean,
+    roll_count?: number,
+    rolls?: AttackRoll[],
+}
+
+type AttackRoll = {
+    attacker_dice: number[],
+    defender_dice: number[],
+    attacker_losses: number,
+    defender_losses: number,
 }
 
 type MoveEvent = MoveRequest;
@@ -204,10 +262,33 @@ type PhaseChangedEvent = {
     new_player: string,
     old_phase: Phase,
     new_phase: Phase,
+    turn_deadline?: string,
+}
+
+type ResignedEvent = {
+    player: string,
+    heir?: string,
+    kicked: boolean,
+}
+
+type TimeoutEvent = {
+    player: string,
+    abandoned: boolean,
+    passed?: boolean,
 }
 
 type StatsChangedEvent = {
     updates: { [name: string]: StatsUpdate },
+    teams?: { [team: string]: TeamStats },
+    next_spoils_bonus?: number,
+}
+
+type TeamStats = {
+    players: string[],
+    territories: number,
+    troops: number,
+    reinforcements: number,
+    eliminated: boolean,
 }
 
 type StatsUpdate = {
@@ -254,10 +335,18 @@ async function fetchGameState(gameId: string): Promise<GameState> {
 }
 
 function advanceGameState(current: GameState, event: GameEvent): GameState {
+    if (!event.snapshot && event.seq <= current.seq) {
+        // Already applied, e.g. from both the action response and the websocket.
+        return current;
+    }
+    return {...applyGameEvent(current, event), seq: event.seq};
+}
+
+function applyGameEvent(current: GameState, event: GameEvent): GameState {
     console.log(`advancing game state with event: ${JSON.stringify(event)}`);
-    if (event.deploy) {
+    if (event.deploy || event.placed) {
         const updatedTerrits = new Map(current.territs);
-        for (const [territName, troops] of Object.entries(event.deploy.deployments)) {
+        for (const [territName, troops] of Object.entries((event.deploy ?? event.placed)!.deployments)) {
             const territ = {...updatedTerrits.get(territName)!};
             territ.troops += troops;
             updatedTerrits.set(territName, territ);
@@ -288,7 +377,15 @@ function advanceGameState(current: GameState, event: GameEvent): GameState {
         updatedTerrits.set(moveEvent.to, toTerrit);
         return {...current, territs: updatedTerrits};
     } else if (event.phase_changed) {
-        return {...current, active_player: event.phase_changed.new_player, phase: event.phase_changed.new_phase };
+        return {...current, active_player: event.phase_changed.new_player, phase: event.phase_changed.new_phase, turn_deadline: event.phase_changed.turn_deadline };
+    } else if (event.timeout) {
+        const timeout = event.timeout;
+        const newPlayers = current.players.map(player => player.name == timeout.player ? {...player, abandoned: timeout.abandoned} : player);
+        const newPlayerMap = new Map();
+        for (const player of newPlayers) {
+            newPlayerMap.set(player.name, player);
+        }
+        return {...current, players: newPlayers, playerMap: newPlayerMap};
     } else if (event.stats_changed) {
         const newPlayers: Player[] = [...current.players];
         for (const idx in newPlayers) {
@@ -302,7 +399,7 @@ function advanceGameState(current: GameState, event: GameEvent): GameState {
         for (const player of newPlayers) {
             newPlayerMap.set(player.name, player);
         }
-        return {...current, players: newPlayers, playerMap: newPlayerMap};
+        return {...current, players: newPlayers, playerMap: newPlayerMap, next_spoils_bonus: event.stats_changed.next_spoils_bonus};
     } else if (event.player_joined) {
         const newPlayers: Player[] = [...current.players];
         newPlayers.push(event.player_joined);
@@ -310,6 +407,27 @@ function advanceGameState(current: GameState, event: GameEvent): GameState {
         const newPlayerMap = new Map(current.playerMap);
         newPlayerMap.set(event.player_joined.name, event.player_joined);
         return {...current, players: newPlayers, playerMap: newPlayerMap};
+    } else if (event.resigned) {
+        // A snapshot with the new owners follows.
+        return current;
+    } else if (event.claimed) {
+        const updatedTerrits = new Map(current.territs);
+        const territ = {...updatedTerrits.get(event.claimed.territory)!};
+        territ.owner = event.claimed.player;
+        territ.troops = event.claimed.troops;
+        updatedTerrits.set(event.claimed.territory, territ);
+        return {...current, territs: updatedTerrits};
+    } else if (event.bid) {
+        // The auction is in the phase that follows.
+        return current;
+    } else if (event.team_chosen) {
+        const name = event.team_chosen.target ?? event.team_chosen.player;
+        const newPlayers = current.players.map(player => player.name == name ? {...player, team: event.team_chosen!.team} : player);
+        const newPlayerMap = new Map(current.playerMap);
+        for (const player of newPlayers) {
+            newPlayerMap.set(player.name, player);
+        }
+        return {...current, players: newPlayers, playerMap: newPlayerMap};
     } else if (event.snapshot) {
         const playerMap = new Map();
         for (const player of event.snapshot.players) {
@@ -408,6 +526,7 @@ function App(props: AppProps) {
     const [clientAdvanceState, setClientAdvanceState] = React.useState<ClientAdvanceState|null>(null);
     const [clientReinforceState, setClientReinforceState] = React.useState<ClientReinforceState|null>(null);
     const [selection, setSelection] = React.useState<string|null>(null);
+    const [blitz, setBlitz] = React.useState<boolean>(false);
     const [hover, setHover] = React.useState<Hover>({ territory: null, token: null });
 
     let phasePanel = null;
@@ -429,18 +548,43 @@ function App(props: AppProps) {
         let overlays: React.ReactElement[] = [];
         let highlights: string[] = [];
         let arrows: React.ReactElement[] = [];
+        // Every seat watches the game, since events such as a timed out or
+        // kicked turn can end the active player's turn without their input.
+        nonRenderingComponents.push(<Websocket key="websocket" gameId={props.gameId} since={gameState.seq} applyEvent={applyEvent} />);
         let selectionHandler: ((name: string | null) => void) | undefined = undefined;
 
+        let resignHandler: (() => void) | undefined = undefined;
+        let kickHandler: ((player: string) => void) | undefined = undefined;
+        const thisPlayer = gameState.playerMap.get(props.player);
+        if (!phase.lobby && !phase.game_over && thisPlayer && !thisPlayer.eliminated) {
+            resignHandler = async () => {
+                if (confirm('Resign from this game?')) {
+                    await sendAction(props.gameId, { resign: { player: props.player } });
+                }
+            };
+            // The admin role passes to the first person still in the game.
+            if (props.player == gameState.players.find(p => !p.eliminated && !p.bot)?.name) {
+                kickHandler = async (player: string) => {
+                    if (confirm(`Kick ${player} from this game?`)) {
+                        await sendAction(props.gameId, { kick: { player: props.player, target: player } });
+                    }
+                };
+            }
+        }
+
         controlPanels.push(
-            <ControlPanel key="player-stats" players={gameState.players} activePlayer={gameState.active_player} thisPlayer={props.player} territs={territs} />
+            <ControlPanel key="player-stats" players={gameState.players} activePlayer={gameState.active_player} thisPlayer={props.player} territs={territs} onResign={resignHandler} onKick={kickHandler} />
         );
+        if (gameState.turn_deadline) {
+            controlPanels.push(<TurnClock key="turn-clock" deadline={gameState.turn_deadline} />);
+        }
 
         {
             const hoveredTerrit = hover.territory || hover.token;
             if (hoveredTerrit) {
                 const territData = territs.get(hoveredTerrit);
                 const owner = territData ? territData.owner : '';
-                const troops = territData ? territData.troops : 0;
+                const troops = territData && !territData.hidden ? territData.troops : null;
                 const territProps = territsImmut.get(hoveredTerrit)!;
                 controlPanels.push(<div key="spacer" className="expand" />);
                 controlPanels.push(<TerritoryDetails key="territ-details" name={hoveredTerrit} owner={owner} troops={troops} neighbours={territProps.neighbours} />);
@@ -450,24 +594,33 @@ function App(props: AppProps) {
         if (phase.lobby) {
             let startGameHandler: (() => void) | undefined = undefined;
             let joinGameHandler: (() => void) | undefined = undefined;
-            if (props.player == gameState.players[0].name) {
+            let addBotHandler: ((kind: string) => void) | undefined = undefined;
+            let chooseTeamHandler: ((team: string) => void) | undefined = undefined;
+            // The admin role passes to the first person still in the game.
+            if (props.player == gameState.players.find(p => !p.eliminated && !p.bot)?.name) {
                 startGameHandler = async () => {
                     await sendAction(props.gameId, { start_game: { player: props.player} });
                 };
+                addBotHandler = async (kind: string) => {
+                    await addBot(props.gameId, kind);
+                };
             } else if (!gameState.playerMap.has(props.player)) {
                 joinGameHandler = async () => {
                     await sendAction(props.gameId, { join_game: { player: props.player} });
                 };
             }
-            phasePanel = <LobbyPanel onStartGame={startGameHandler} onJoinGame={joinGameHandler} />
-            nonRenderingComponents.push(<Websocket key="websocket" gameId={props.gameId} applyEvent={applyEvent} />);
+            if (gameState.settings?.teams && thisPlayer) {
+                chooseTeamHandler = async (team: string) => {
+                    await sendAction(props.gameId, { choose_team: { player: props.player, team: team } });
+                };
+            }
+            phasePanel = <LobbyPanel team={thisPlayer?.team} onStartGame={startGameHandler} onJoinGame={joinGameHandler} onAddBot={addBotHandler} onChooseTeam={chooseTeamHandler} />
         } else if (gameState.active_player !== props.player) {
             if (gameState.playerMap.get(props.player)!.eliminated) {
                 phasePanel = <EliminatedPanel />
             } else {
                 phasePanel = <WaitingPanel />;
             }
-            nonRenderingComponents.push(<Websocket key="websocket" gameId={props.gameId} applyEvent={applyEvent} />);
         } else if (phase.spoils) {
             const playSpoils = async (spoils: string[]) => {
                 const events = await sendAction(props.gameId, { spoils: { player: props.player, spoils: spoils }});
@@ -475,20 +628,53 @@ function App(props: AppProps) {
                     applyEvent(event);
                 }
             };
-            phasePanel = <SpoilsPanel thisPlayer={props.player} spoils={gameState.playerMap.get(props.player)!.spoils} mandatory={phase.spoils.mandatory} territs={territs} onPlaySpoils={playSpoils} />;
-        } else if (phase.deploy) {
+            phasePanel = <SpoilsPanel thisPlayer={props.player} spoils={gameState.playerMap.get(props.player)!.spoils} mandatory={phase.spoils.mandatory} nextBonus={gameState.next_spoils_bonus} territs={territs} onPlaySpoils={playSpoils} />;
+        } else if (phase.draft) {
+            const draft = phase.draft;
+            const unclaimed = (name: string) => {
+                const territ = territs.get(name)!;
+                return !territ.owner;
+            };
+            let bidHandler: ((troops: number) => void) | undefined = undefined;
+            if (draft.mode == 'bid') {
+                selectionHandler = (name: string | null) => setSelection(name && unclaimed(name) ? name : null);
+                bidHandler = async (troops: number) => {
+                    const bid = draft.auction ? { player: props.player, troops: troops } : { player: props.player, territory: selection!, troops: troops };
+                    const events = await sendAction(props.gameId, { bid: bid });
+                    for (const event of events) {
+                        applyEvent(event);
+                    }
+                    setSelection(null);
+                };
+            } else {
+                selectionHandler = async (name: string | null) => {
+                    if (name && unclaimed(name)) {
+                        const events = await sendAction(props.gameId, { claim: { player: props.player, territory: name } });
+                        for (const event of events) {
+                            applyEvent(event);
+                        }
+                    }
+                };
+            }
+            if (draft.auction) {
+                highlights = [draft.auction.territory];
+            }
+            phasePanel = <DraftPanel pool={thisPlayer?.pool ?? 0} auction={draft.auction} selection={selection ?? undefined} onBid={bidHandler} />;
+        } else if (phase.deploy || phase.placement) {
             const localDeployState = clientDeployState ?? { reinforcementsUsed: 0, request: { player: props.player, deployments: {} }};
-            const reinforcementsRemaining = phase.deploy.reinforcements - localDeployState.reinforcementsUsed;
+            const reinforcementsTotal = phase.deploy ? phase.deploy.reinforcements : phase.placement!.troops;
+            const reinforcementsRemaining = reinforcementsTotal - localDeployState.reinforcementsUsed;
             selectionHandler = name => setSelection(name);
             const onDeploy = async () => {
-                const events = await sendAction(props.gameId, { deploy: localDeployState.request });
+                const request = phase.deploy ? { deploy: localDeployState.request } : { place: localDeployState.request };
+                const events = await sendAction(props.gameId, request);
                 for (const event of events) {
                     applyEvent(event);
                 }
                 setClientDeployState(null);
                 setSelection(null);
             };
-            phasePanel = <DeployPanel onDeploy={onDeploy} reinforcementsRemaining={reinforcementsRemaining} reinforcementsTotal={phase.deploy.reinforcements} />;
+            phasePanel = <DeployPanel onDeploy={onDeploy} reinforcementsRemaining={reinforcementsRemaining} reinforcementsTotal={reinforcementsTotal} />;
             if (selection && territs.get(selection)!.owner == props.player) {
                 const deployment = localDeployState.request.deployments[selection] ?? 0;
                 const onDeployChange = (event: React.ChangeEvent<HTMLInputElement>) => {
@@ -517,13 +703,12 @@ function App(props: AppProps) {
             selectionHandler = async (newSelection: string | null) => {
                 if (newSelection && selection && territs.get(selection)!.owner == props.player && territs.get(newSelection)!.owner != props.player) {
                     // This selection is an attack!
-                    const request = {
-                        attack: {
-                            player: props.player,
-                            from: selection,
-                            to: newSelection,
-                        }
+                    const attack = {
+                        player: props.player,
+                        from: selection,
+                        to: newSelection,
                     };
+                    const request = blitz ? { blitz: attack } : { attack };
                     const events = await sendAction(props.gameId, request);
                     for (const event of events) {
                         applyEvent(event);
@@ -539,7 +724,7 @@ function App(props: AppProps) {
                 }
                 setSelection(null);
             };
-            phasePanel = <AttackPanel onFinish={onFinish} />
+            phasePanel = <AttackPanel blitz={blitz} onBlitzChange={setBlitz} onFinish={onFinish} />
 
             if (hover && hover.token) {
                 const name = hover.token;
@@ -708,9 +893,9 @@ function App(props: AppProps) {
                 }
                 troopsEl = (
                     <map-troops
-                        amount={troops}
+                        amount={data.hidden ? NaN : troops}
                         additional={additionalTroops}
-                        color={gameState.playerMap.get(data.owner)!.color}
+                        color={gameState.playerMap.get(data.owner)?.color ?? 'grey'}
                         selected={isTokenSelected}
                         hovered={isTokenHovered}
                         highlighted={isHighlighted} />
@@ -761,10 +946,17 @@ type ActionRequest = {
     spoils?: PlaySpoilsRequest,
     deploy?: DeployRequest,
     attack?: AttackRequest,
+    blitz?: BlitzRequest,
     advance?: MoveRequest,
     end_attack?: EndPhaseRequest,
     reinforce?: MoveRequest,
     end_reinforce?: EndPhaseRequest,
+    resign?: ResignRequest,
+    kick?: KickRequest,
+    choose_team?: TeamRequest,
+    claim?: ClaimRequest,
+    bid?: BidRequest,
+    place?: DeployRequest,
 }
 
 type JoinGameRequest = {
@@ -791,6 +983,29 @@ type AttackRequest = {
     to: string,
 }
 
+type BlitzRequest = AttackRequest & {
+    min_troops?: number,
+    max_rolls?: number,
+    detail?: boolean,
+}
+
+type ClaimRequest = {
+    player: string,
+    territory: string,
+}
+
+type BidRequest = {
+    player: string,
+    territory?: string,
+    troops: number,
+}
+
+type TeamRequest = {
+    player: string,
+    target?: string,
+    team: string,
+}
+
 type MoveRequest = {
     player: string,
     from: string,
@@ -802,7 +1017,23 @@ type EndPhaseRequest = {
     player: string
 }
 
+type ResignRequest = {
+    player: string,
+    heir?: string,
+}
+
+type KickRequest = {
+    player: string,
+    target: string,
+    heir?: string,
+}
+
 async function sendAction(gameId: string, action: ActionRequest): Promise<GameEvent[]> {
+    // Prefer the websocket, which saves a round-trip per action.
+    const sent = sendActionOverSocket(action);
+    if (sent !== null) {
+        return sent;
+    }
     const response = await fetch(`/api/v1/game/${gameId}`, {
         method: 'POST',
         body: JSON.stringify(action),
@@ -814,6 +1045,18 @@ async function sendAction(gameId: string, action: ActionRequest): Promise<GameEv
     return json as GameEvent[];
 }
 
+async function addBot(gameId: string, kind: string): Promise<GameEvent[]> {
+    const response = await fetch(`/api/v1/game/${gameId}/bots`, {
+        method: 'POST',
+        body: JSON.stringify({ kind: kind }),
+    });
+    const json = await response.json();
+    if (!response.ok) {
+        throw Error(`failed to add bot: ${json.error}`);
+    }
+    return json as GameEvent[];
+}
+
 function LoadingView() {
     return (
         <div style={{width: '100%', height: '100%', textAlign: 'center', display: 'flex', alignItems: 'center'}}>
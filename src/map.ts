@@ -323,7 +323,8 @@ class MapViewElement extends MapElement {
         ctx.beginPath();
         for (const token of tokenList) {
             const center = territoryTransform.transformPoint(token.territory!.center);
-            ctx.fillText(token.amount.toString(), center.x, center.y);
+            // Troops hidden by fog of war are NaN.
+            ctx.fillText(isNaN(token.amount) ? '?' : token.amount.toString(), center.x, center.y);
         }
         ctx.font = `${TOKEN_ADDITIONAL_RADIUS * 1.5}px sans-serif`;
         for (const token of tokenList) {
@@ -1,5 +1,6 @@
 interface WebsocketProps {
     gameId: string,
+    since: number,
     applyEvent: (event: GameEvent) => void,
 }
 
@@ -10,18 +11,129 @@ function getWebsocketScheme(): string {
     return 'ws';
 }
 
+const RECONNECT_DELAY_MS = 1000;
+
+type ActionAck = {
+    request_id: string,
+    events?: GameEvent[],
+    error?: string,
+}
+
+// WatchMessage is either an event, which always carries a sequence number, or
+// the acknowledgement of an action sent over the websocket, which never does.
+type WatchMessage = GameEvent & {
+    ack?: ActionAck,
+}
+
+type PendingAction = {
+    resolve: (events: GameEvent[]) => void,
+    reject: (error: Error) => void,
+}
+
+// actionSocket is the open websocket of the game being watched, if any.
+let actionSocket: WebSocket | null = null;
+const pendingActions = new Map<string, PendingAction>();
+let nextRequestId = 1;
+
+// sendActionOverSocket sends the action over the open websocket, which saves
+// the round-trip of a POST. It returns null if there is no open websocket.
+function sendActionOverSocket(action: ActionRequest): Promise<GameEvent[]> | null {
+    const ws = actionSocket;
+    if (ws === null || ws.readyState !== WebSocket.OPEN) {
+        return null;
+    }
+    const requestId = `${nextRequestId++}`;
+    return new Promise((resolve, reject) => {
+        pendingActions.set(requestId, { resolve: resolve, reject: reject });
+        ws.send(JSON.stringify({ request_id: requestId, action: action }));
+    });
+}
+
+function handleAck(ack: ActionAck) {
+    const pending = pendingActions.get(ack.request_id);
+    if (!pending) {
+        return;
+    }
+    pendingActions.delete(ack.request_id);
+    if (ack.error) {
+        pending.reject(Error(`failed to send action: ${ack.error}`));
+    } else {
+        pending.resolve(ack.events ?? []);
+    }
+}
+
+// failPendingActions gives up on the acks of a websocket that closed. The
+// actions may or may not have been applied, which the events resumed on the
+// next connection tell.
+function failPendingActions() {
+    for (const pending of pendingActions.values()) {
+        pending.reject(Error('failed to send action: connection closed'));
+    }
+    pendingActions.clear();
+}
+
 function Websocket(props: WebsocketProps) {
+    const lastSeq = React.useRef(props.since);
     React.useEffect(() => {
         const scheme = getWebsocketScheme();
-        const ws = new WebSocket(`${scheme}://${document.location.host}/api/v1/game/${props.gameId}/watch`);
-        ws.onclose = event => {
-            console.log("connection closed:", event);
-        };
-        ws.onmessage = event => {
-            const message = JSON.parse(event.data) as GameEvent;
+        let ws: WebSocket | null = null;
+        let eventSource: EventSource | null = null;
+        let reconnectTimer: number | null = null;
+        let closing = false;
+        const onMessage = (data: string) => {
+            const message = JSON.parse(data) as WatchMessage;
+            if (message.ack) {
+                handleAck(message.ack);
+                return;
+            }
+            if (typeof message.seq === 'number') {
+                lastSeq.current = message.seq;
+            }
             props.applyEvent(message);
         };
-        return () => { console.log('closing'); ws.close(); };  
+        const connectEventSource = () => {
+            // The browser reconnects on its own, resuming with Last-Event-ID.
+            eventSource = new EventSource(`/api/v1/game/${props.gameId}/events?since=${lastSeq.current}`);
+            eventSource.onmessage = event => onMessage(event.data);
+        };
+        const connect = () => {
+            let opened = false;
+            ws = new WebSocket(`${scheme}://${document.location.host}/api/v1/game/${props.gameId}/watch?since=${lastSeq.current}`);
+            const socket = ws;
+            ws.onopen = () => {
+                opened = true;
+                actionSocket = socket;
+            };
+            ws.onclose = event => {
+                console.log("connection closed:", event);
+                if (actionSocket === socket) {
+                    actionSocket = null;
+                    failPendingActions();
+                }
+                if (closing) {
+                    return;
+                }
+                if (!opened) {
+                    // Websockets may be blocked by a proxy, fall back to Server-Sent Events.
+                    console.log("websocket failed, using event stream");
+                    connectEventSource();
+                } else {
+                    // Resume from the last event we saw.
+                    reconnectTimer = window.setTimeout(connect, RECONNECT_DELAY_MS);
+                }
+            };
+            ws.onmessage = event => onMessage(event.data);
+        };
+        connect();
+        return () => {
+            console.log('closing');
+            closing = true;
+            if (reconnectTimer !== null) {
+                window.clearTimeout(reconnectTimer);
+            }
+            ws?.close();
+            eventSource?.close();
+        };
     }, [props.gameId]);
     return null;
 }